@@ -0,0 +1,84 @@
+package numprint
+
+import (
+	"bytes"
+	"context"
+	"io"
+)
+
+// Appender prints the digits of a sequence a piece at a time as more of
+// them become available. Each call to Append prints only the digits that
+// are new since the last call, picking up the layout where the last call
+// left off, so that the pieces together read the same as printing all the
+// digits at once with Fprint. An Appender uses the same defaults as
+// Fprint.
+type Appender struct {
+	buffer           bytes.Buffer
+	printer          *printer
+	next             int
+	truncationMarker string
+}
+
+// NewAppender returns an Appender that prints with options. end is one
+// past the largest position that will be printed. The Appender uses end
+// only to compute the width of the left margin. Appender ignores the
+// Reverse, MarkdownFence, HighlightLast, and OnRow options as these need
+// to know when printing is done. Options that apply only at the end, such
+// as RowSuffix on the last row, PadLastRow, TrailingLF, and
+// TruncationMarker, take effect when Finish is called.
+func NewAppender(end int, options ...Option) *Appender {
+	settings := printSettings(options)
+	settings.reverse = false
+	settings.markdownFence = false
+	settings.highlightLast = ""
+	settings.onRow = nil
+	result := &Appender{truncationMarker: settings.truncationMarker}
+	result.printer = newPrinter(&result.buffer, end, settings)
+	return result
+}
+
+// Append prints the digits of s from where the last call to Append left
+// off up to but not including newEnd to w. The first call starts at
+// position 0. Append does nothing more than write any output that is
+// still pending if newEnd is not past where the last call left off. Append
+// returns the number of bytes written to w and any error encountered.
+// After an error other than one writing to w, Append prints nothing more
+// and keeps returning that error.
+func (a *Appender) Append(w io.Writer, s Printable, newEnd int) (
+	written int, err error) {
+	if newEnd > a.next {
+		fromIterator(
+			context.Background(), s.AllInRange(a.next, newEnd), a.printer)
+		a.next = newEnd
+	}
+	a.printer.flush()
+	n, err := a.buffer.WriteTo(w)
+	if err == nil {
+		err = a.printer.Err()
+	}
+	return int(n), err
+}
+
+// Finish writes to w what goes after the last digit such as the
+// RowSuffix of the last row, PadLastRow padding, the TrailingLF, and the
+// AppendChecksumLine checksum. Finish writes the TruncationMarker only if
+// Limit or MaxRows cut printing short because an Appender cannot know
+// whether more digits follow. Call Finish once after the last call to
+// Append. Finish returns the number of bytes written to w and any error
+// encountered.
+func (a *Appender) Finish(w io.Writer) (written int, err error) {
+	if a.truncationMarker != "" && a.printer.truncated() {
+		a.printer.Append(a.truncationMarker)
+	}
+	a.printer.Finish()
+	n, err := a.buffer.WriteTo(w)
+	if err == nil {
+		err = a.printer.Err()
+	}
+	return int(n), err
+}
+
+// Next returns the position where the next call to Append starts.
+func (a *Appender) Next() int {
+	return a.next
+}
@@ -0,0 +1,81 @@
+package numprint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAppender(t *testing.T) {
+	options := []Option{DigitsPerRow(10), Highlight(map[int]string{12: "1"})}
+	appender := NewAppender(25, options...)
+	var builder strings.Builder
+	total := 0
+	for _, end := range []int{3, 3, 14, 25} {
+		written, err := appender.Append(&builder, newFakeNumber(), end)
+		assert.NoError(t, err)
+		assert.Equal(t, end, appender.Next())
+		total += written
+	}
+	expected := Sprint(newFakeNumber(), UpTo(25), options...)
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, len(expected), total)
+}
+
+func TestAppenderPieces(t *testing.T) {
+	appender := NewAppender(20, DigitsPerRow(10))
+	var builder strings.Builder
+	_, err := appender.Append(&builder, newFakeNumber(), 8)
+	assert.NoError(t, err)
+	assert.Equal(t, "  0.12345 678", builder.String())
+	builder.Reset()
+	_, err = appender.Append(&builder, newFakeNumber(), 12)
+	assert.NoError(t, err)
+	assert.Equal(t, "90\n10  12", builder.String())
+}
+
+func TestAppenderError(t *testing.T) {
+	appender := NewAppender(20, Radix(8))
+	var builder strings.Builder
+	_, err := appender.Append(&builder, newFakeNumber(), 10)
+	assert.Error(t, err)
+	_, err = appender.Append(&builder, newFakeNumber(), 12)
+	assert.Error(t, err)
+	assert.Equal(t, "0.12345 67", builder.String())
+}
+
+func TestAppenderFinish(t *testing.T) {
+	options := []Option{
+		DigitsPerRow(10),
+		RowSuffix(" |"),
+		PadLastRow(true),
+		TrailingLF(true),
+	}
+	appender := NewAppender(25, options...)
+	var builder strings.Builder
+	_, err := appender.Append(&builder, newFakeNumber(), 14)
+	assert.NoError(t, err)
+	_, err = appender.Append(&builder, newFakeNumber(), 25)
+	assert.NoError(t, err)
+	written, err := appender.Finish(&builder)
+	assert.NoError(t, err)
+	assert.Positive(t, written)
+	expected := Sprint(newFakeNumber(), UpTo(25), options...)
+	assert.Equal(t, expected, builder.String())
+}
+
+func TestAppenderFinishTruncated(t *testing.T) {
+	options := []Option{
+		DigitsPerRow(10), MaxRows(1), TruncationMarker("...")}
+	appender := NewAppender(25, options...)
+	var builder strings.Builder
+	_, err := appender.Append(&builder, newFakeNumber(), 25)
+	assert.NoError(t, err)
+	_, err = appender.Finish(&builder)
+	assert.NoError(t, err)
+	assert.Equal(
+		t,
+		Sprint(newFakeNumber(), UpTo(25), options...),
+		builder.String())
+}
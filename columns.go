@@ -0,0 +1,204 @@
+package numprint
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+// Gutter sets the string that FprintColumns writes between sources. The
+// default is four spaces.
+func Gutter(gutter string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.gutter = gutter
+	})
+}
+
+// CountEachSource makes FprintColumns show the digit count in the left
+// margin of every source if on is true and of only the first source if on
+// is false. CountEachSource has no effect unless the digit count is shown.
+// The default is true.
+func CountEachSource(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countFirstSourceOnly = !on
+	})
+}
+
+// FprintColumns works like Fprint except that it prints several sources
+// side by side. Each line of output has the same row of each source
+// separated by the gutter. Each source is laid out the same way so that the
+// rows line up. Where one source has a row and another does not, the other
+// gets a row of missing digits. FprintColumns ignores the Reverse, Ruler,
+// ColumnHeaders, OnRow, OnProgress, OnDigit, SkipFirstCount,
+// MarkdownFence, TruncationMarker, Checksum, and AppendChecksumLine
+// options.
+// FprintColumns returns the number of bytes written and any error
+// encountered.
+func FprintColumns(
+	w io.Writer,
+	sources []Printable,
+	p Positions,
+	options ...Option) (written int, err error) {
+	settings := printSettings(options)
+	settings.reverse = false
+	settings.ruler = false
+	settings.columnHeaders = false
+	settings.onRow = nil
+	settings.onProgress = nil
+	settings.onDigit = nil
+	settings.skipFirstCount = false
+	settings.markdownFence = false
+	settings.truncationMarker = ""
+	settings.checksum = nil
+	settings.appendChecksumLine = false
+	end := p.End()
+	sourceSettings := make([]*printerSettings, len(sources))
+	sourceRows := make([]map[int]string, len(sources))
+	rowIndexes := make(map[int]struct{})
+	for i, source := range sources {
+		sourceSettings[i] = settings.forSource(i)
+		rows, err := formatRows(source, p, end, sourceSettings[i])
+		if err != nil {
+			return 0, err
+		}
+		for rowIndex := range rows {
+			rowIndexes[rowIndex] = struct{}{}
+		}
+		sourceRows[i] = rows
+	}
+	widths := make([]int, len(sources))
+	for i := range sources {
+		for rowIndex := range rowIndexes {
+			if _, ok := sourceRows[i][rowIndex]; !ok {
+				sourceRows[i][rowIndex] = sourceSettings[i].missingRow(
+					end, rowIndex)
+			}
+			widths[i] = max(
+				widths[i],
+				utf8.RuneCountInString(sourceRows[i][rowIndex]))
+		}
+	}
+	cWriter := &countingWriter{delegate: w}
+	writer := settings.newBufferedWriter(cWriter)
+	err = writeColumns(
+		writer,
+		slices.Sorted(maps.Keys(rowIndexes)),
+		sourceRows,
+		widths,
+		settings)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	return cWriter.bytesWritten, err
+}
+
+// forSource returns the settings for printing the source at index in
+// FprintColumns.
+func (p *printerSettings) forSource(index int) *printerSettings {
+	result := *p
+	result.trailingLineFeed = false
+
+	// Indenting the whole output indents the sources.
+	result.indent = 0
+	if index > 0 && p.countFirstSourceOnly {
+		result.showCount = false
+	}
+	return &result
+}
+
+// missingRow returns the row at rowIndex formatted as if all its digits
+// were missing.
+func (p *printerSettings) missingRow(maxDigits, rowIndex int) string {
+	var builder strings.Builder
+	var printer rawPrinter
+	printer.Init(&builder, maxDigits, p.withLayout(maxDigits))
+	if printer.digitsPerRow <= 0 {
+		return ""
+	}
+	printer.skipRows(rowIndex)
+	missing := p.missingText()
+	for i := 0; i < printer.digitsPerRow; i++ {
+		if printer.realIndex(printer.index) >= maxDigits {
+			break
+		}
+		if p.missingDigitFunc != nil {
+			printer.Consume(
+				p.missingDigitFunc(printer.countAt(printer.index)))
+		} else {
+			printer.ConsumeText(missing)
+		}
+	}
+	printer.flush()
+	return builder.String()
+}
+
+func formatRows(
+	s Printable,
+	p Positions,
+	end int,
+	settings *printerSettings) (map[int]string, error) {
+	rows := make(map[int]string)
+	rowSettings := *settings
+	rowSettings.onRow = func(rowIndex int, text string) {
+		rows[rowIndex] = text
+	}
+	printer := newPrinter(io.Discard, end, &rowSettings)
+	fromSequenceWithPositions(context.Background(), s, p, printer)
+	printer.Finish()
+	return rows, printer.Err()
+}
+
+func writeColumns(
+	w bufferedWriter,
+	rowIndexes []int,
+	sourceRows []map[int]string,
+	widths []int,
+	settings *printerSettings) error {
+	for i, rowIndex := range rowIndexes {
+		if i > 0 {
+			if _, err := w.WriteString(settings.newline()); err != nil {
+				return err
+			}
+			if _, err := w.WriteString(settings.rowSeparator); err != nil {
+				return err
+			}
+		}
+		if err := writeColumnsRow(
+			w, rowIndex, sourceRows, widths, settings.gutter); err != nil {
+			return err
+		}
+	}
+	if settings.trailingLineFeed {
+		_, err := w.WriteString(settings.newline())
+		return err
+	}
+	return nil
+}
+
+func writeColumnsRow(
+	w bufferedWriter,
+	rowIndex int,
+	sourceRows []map[int]string,
+	widths []int,
+	gutter string) error {
+	for i, rows := range sourceRows {
+		text := rows[rowIndex]
+		if _, err := w.WriteString(text); err != nil {
+			return err
+		}
+		if i == len(sourceRows)-1 {
+			break
+		}
+		padding := widths[i] - utf8.RuneCountInString(text)
+		if err := writeSpaces(w, padding); err != nil {
+			return err
+		}
+		if _, err := w.WriteString(gutter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package numprint
+
+import (
+	"crypto/sha256"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFprintColumns(t *testing.T) {
+	a, _ := FromString("1415926535897932")
+	b, _ := FromString("7182818284")
+	var sb strings.Builder
+	n, err := FprintColumns(
+		&sb,
+		[]Printable{a, b},
+		UpTo(16),
+		DigitsPerRow(6),
+		DigitsPerColumn(3),
+		Gutter(" | "))
+	assert.NoError(t, err)
+	expected := `  0.141 592 |   0.718 281
+ 6  653 589 |  6  828 4
+12  793 2   | 12  ... .`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), n)
+}
+
+func TestFprintColumnsIgnoredOptions(t *testing.T) {
+	a, _ := FromString("1415926535897932")
+	b, _ := FromString("7182818284")
+	sources := []Printable{a, b}
+	var expected strings.Builder
+	_, err := FprintColumns(&expected, sources, UpTo(12), DigitsPerRow(6))
+	assert.NoError(t, err)
+	checksum := sha256.New()
+	var sb strings.Builder
+	_, err = FprintColumns(
+		&sb,
+		sources,
+		UpTo(12),
+		DigitsPerRow(6),
+		MarkdownFence(""),
+		TruncationMarker("..."),
+		Checksum(checksum),
+		AppendChecksumLine(true))
+	assert.NoError(t, err)
+	assert.Equal(t, expected.String(), sb.String())
+	assert.Equal(t, sha256.New().Sum(nil), checksum.Sum(nil))
+}
+
+func TestFprintColumnsCountFirstSourceOnly(t *testing.T) {
+	a, _ := FromString("123456")
+	b, _ := FromString("654321")
+	var sb strings.Builder
+	_, err := FprintColumns(
+		&sb,
+		[]Printable{a, b},
+		UpTo(6),
+		DigitsPerRow(3),
+		LeadingDecimal(false),
+		CountEachSource(false),
+		TrailingLF(true))
+	assert.NoError(t, err)
+	expected := `0  123    654
+3  456    321
+`
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestFprintColumnsSparse(t *testing.T) {
+	a, _ := FromString("123456789")
+	var pb PositionsBuilder
+	pb.AddRange(0, 2).AddRange(20, 22)
+	var sb strings.Builder
+	_, err := FprintColumns(
+		&sb,
+		[]Printable{a, fakeNumber{}},
+		pb.Build(),
+		DigitsPerRow(10),
+		DigitsPerColumn(0))
+	assert.NoError(t, err)
+	expected := `  0.12      0.12........
+20  ..    20  12`
+	assert.Equal(t, expected, sb.String())
+}
+
+func TestFprintColumnsError(t *testing.T) {
+	var sb strings.Builder
+	_, err := FprintColumns(&sb, []Printable{fakeNumber{}}, UpTo(10), Radix(5))
+	assert.Error(t, err)
+}
@@ -0,0 +1,95 @@
+package numprint
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+)
+
+// CSVHeader writes a header row of "position,digit" with Fcsv if on is
+// true. The default is true.
+func CSVHeader(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.noCSVHeader = !on
+	})
+}
+
+// CSVMissingDigit writes the missing digit character in the digit field for
+// missing digits with Fcsv if on is true. If on is false, the digit field
+// for missing digits is empty. The default is false.
+func CSVMissingDigit(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.csvMissingDigit = on
+	})
+}
+
+// Fcsv writes the digits of s at positions p to w as CSV with one record
+// per position. Each record has the position followed by the digit. Fcsv
+// writes a record for each position in p including positions for which s
+// has no digit. Fcsv uses only the CSVHeader, CSVMissingDigit,
+// MissingDigit, DecimalSeparator, LineEnding, and Radix options. Fcsv
+// returns the number of bytes written and any error encountered.
+func Fcsv(w io.Writer, s Printable, p Positions, options ...Option) (
+	written int, err error) {
+	settings := printSettings(options)
+	var digits digitSet
+	if err := digits.Init(settings); err != nil {
+		return 0, err
+	}
+	var missingDigit string
+	if settings.csvMissingDigit {
+		missingDigit = settings.missingText()
+	}
+	cWriter := &countingWriter{delegate: w}
+	writer := csv.NewWriter(cWriter)
+	writer.UseCRLF = settings.newline() == "\r\n"
+	err = writeCSV(writer, s, p, &digits, missingDigit, !settings.noCSVHeader)
+	writer.Flush()
+	if err == nil {
+		err = writer.Error()
+	}
+	return cWriter.bytesWritten, err
+}
+
+func writeCSV(
+	writer *csv.Writer,
+	s Printable,
+	p Positions,
+	digits *digitSet,
+	missingDigit string,
+	header bool) error {
+	if header {
+		if err := writer.Write([]string{"position", "digit"}); err != nil {
+			return err
+		}
+	}
+	record := make([]string, 2)
+	write := func(posit int, digit string) error {
+		record[0] = strconv.Itoa(posit)
+		record[1] = digit
+		return writer.Write(record)
+	}
+	for pr := range p.All() {
+		next := pr.Start
+		for posit, digit := range s.AllInRange(pr.Start, pr.End) {
+			if err := digits.Check(posit, digit); err != nil {
+				return err
+			}
+			for ; next < posit; next++ {
+				if err := write(next, missingDigit); err != nil {
+					return err
+				}
+			}
+			if err := write(posit, strconv.Itoa(digit)); err != nil {
+				return err
+			}
+			next = posit + 1
+		}
+		for ; next < pr.End; next++ {
+			if err := write(next, missingDigit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
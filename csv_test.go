@@ -0,0 +1,63 @@
+package numprint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCsv(t *testing.T) {
+	var builder strings.Builder
+	var pb PositionsBuilder
+	n, err := Fcsv(
+		&builder,
+		newFakeNumberRange(3, 100),
+		pb.AddRange(1, 5).Add(10).Build())
+	assert.NoError(t, err)
+	expected := `position,digit
+1,
+2,
+3,4
+4,5
+10,1
+`
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, len(expected), n)
+}
+
+func TestCsvOptions(t *testing.T) {
+	var builder strings.Builder
+	_, err := Fcsv(
+		&builder,
+		newFakeNumberRange(0, 3),
+		UpTo(5),
+		CSVHeader(false),
+		CSVMissingDigit(true),
+		MissingDigit('"'))
+	assert.NoError(t, err)
+	expected := `0,1
+1,2
+2,3
+3,""""
+4,""""
+`
+	assert.Equal(t, expected, builder.String())
+}
+
+func TestCsvErrors(t *testing.T) {
+	var builder strings.Builder
+	_, err := Fcsv(&builder, fakeDigits{1, 11}, UpTo(2))
+	assert.ErrorContains(t, err, "digit 11 at position 1")
+	w := &maxBytesWriter{maxBytes: 100}
+	n, err := Fcsv(w, newFakeNumber(), UpTo(1000))
+	assert.Error(t, err)
+	assert.Equal(t, 100, n)
+}
+
+func TestCSVLineEnding(t *testing.T) {
+	var builder strings.Builder
+	_, err := Fcsv(&builder, newFakeNumber(), UpTo(2), LineEnding("\r\n"))
+	assert.NoError(t, err)
+	assert.Equal(t, "position,digit\r\n0,1\r\n1,2\r\n", builder.String())
+}
@@ -0,0 +1,102 @@
+package numprint
+
+import (
+	"io"
+	"iter"
+)
+
+const (
+	diffHighlight = "1;31"
+)
+
+// Diff returns the positions in p where a and b differ in ascending order.
+// A position where a or b has no digit counts as a difference.
+func Diff(a, b Printable, p Positions) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for pr := range p.All() {
+			if !diffRange(a, b, pr, yield) {
+				return
+			}
+		}
+	}
+}
+
+// FirstDiff returns the first position where a and b differ. If one of a
+// and b is shorter than the other but otherwise the same, FirstDiff
+// returns the position just past the end of the shorter one. ok is false
+// if a and b are the same. FirstDiff stops iterating over a and b as soon
+// as it finds a difference.
+func FirstDiff(a, b Writable) (posit int, ok bool) {
+	nextA, stopA := iter.Pull2(a.All())
+	defer stopA()
+	nextB, stopB := iter.Pull2(b.All())
+	defer stopB()
+	for {
+		positA, digitA, okA := nextA()
+		positB, digitB, okB := nextB()
+		switch {
+		case !okA && !okB:
+			return 0, false
+		case !okA:
+			return positB, true
+		case !okB:
+			return positA, true
+		case positA != positB:
+			return min(positA, positB), true
+		case digitA != digitB:
+			return positA, true
+		}
+	}
+}
+
+// FprintDiff prints a and then b to w with a line feed in between using
+// Fprint. The line feed follows LineEnding. FprintDiff highlights in bold
+// red the digits where a and b differ according to Diff. options work the
+// same as in Fprint except that FprintDiff ignores Highlight. FprintDiff
+// returns the total number of bytes written and any error encountered.
+func FprintDiff(
+	w io.Writer, a, b Printable, p Positions, options ...Option) (
+	written int, err error) {
+	highlight := make(map[int]string)
+	for posit := range Diff(a, b, p) {
+		highlight[posit] = diffHighlight
+	}
+	options = append(options[:len(options):len(options)], Highlight(highlight))
+	written, err = Fprint(w, a, p, options...)
+	if err != nil {
+		return
+	}
+	n, err := io.WriteString(w, printSettings(options).newline())
+	written += n
+	if err != nil {
+		return
+	}
+	n, err = Fprint(w, b, p, options...)
+	written += n
+	return
+}
+
+func diffRange(a, b Printable, pr PositionRange, yield func(int) bool) bool {
+	nextA, stopA := iter.Pull2(a.AllInRange(pr.Start, pr.End))
+	defer stopA()
+	nextB, stopB := iter.Pull2(b.AllInRange(pr.Start, pr.End))
+	defer stopB()
+	positA, digitA, okA := nextA()
+	positB, digitB, okB := nextB()
+	for posit := pr.Start; posit < pr.End; posit++ {
+		hasA := okA && positA == posit
+		hasB := okB && positB == posit
+		if !hasA || !hasB || digitA != digitB {
+			if !yield(posit) {
+				return false
+			}
+		}
+		if hasA {
+			positA, digitA, okA = nextA()
+		}
+		if hasB {
+			positB, digitB, okB = nextB()
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package numprint
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	a := fakeDigits{1, 2, 3, 4, 5, 6}
+	b := fakeDigits{1, 2, 0, 4, 5, 0, 7, 8}
+	var pb PositionsBuilder
+	p := pb.AddRange(0, 4).AddRange(5, 9).Build()
+	assert.Equal(t, []int{2, 5, 6, 7, 8}, slices.Collect(Diff(a, b, p)))
+	assert.Empty(t, slices.Collect(Diff(a, a, UpTo(6))))
+	assert.Equal(t, []int{6, 7}, slices.Collect(Diff(a, a, UpTo(8))))
+	for posit := range Diff(a, b, p) {
+		assert.Equal(t, 2, posit)
+		break
+	}
+}
+
+func TestFirstDiff(t *testing.T) {
+	posit, ok := FirstDiff(fakeDigits{1, 2, 3, 4}, fakeDigits{1, 2, 0, 4})
+	assert.True(t, ok)
+	assert.Equal(t, 2, posit)
+	posit, ok = FirstDiff(fakeDigits{1, 2, 3}, fakeDigits{1, 2, 3, 4, 5})
+	assert.True(t, ok)
+	assert.Equal(t, 3, posit)
+	posit, ok = FirstDiff(fakeDigits{1, 2, 3, 4}, fakeDigits{})
+	assert.True(t, ok)
+	assert.Equal(t, 0, posit)
+	posit, ok = FirstDiff(newFakeNumberRange(2, 5), newFakeNumberRange(0, 5))
+	assert.True(t, ok)
+	assert.Equal(t, 0, posit)
+	_, ok = FirstDiff(fakeDigits{1, 2, 3}, fakeDigits{1, 2, 3})
+	assert.False(t, ok)
+	_, ok = FirstDiff(fakeDigits{}, fakeDigits{})
+	assert.False(t, ok)
+}
+
+func TestFprintDiff(t *testing.T) {
+	var builder strings.Builder
+	a := fakeDigits{1, 2, 3, 4}
+	b := fakeDigits{1, 2, 0, 4}
+	n, err := FprintDiff(&builder, a, b, UpTo(4))
+	assert.NoError(t, err)
+	expected := "0.12\x1b[1;31m3\x1b[0m4\n0.12\x1b[1;31m0\x1b[0m4"
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, len(expected), n)
+	builder.Reset()
+	_, err = FprintDiff(&builder, a, b, UpTo(4), LineEnding("\r\n"))
+	assert.NoError(t, err)
+	expected = "0.12\x1b[1;31m3\x1b[0m4\r\n0.12\x1b[1;31m0\x1b[0m4"
+	assert.Equal(t, expected, builder.String())
+}
@@ -0,0 +1,136 @@
+package numprint
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Digits is a finite sequence of digits held in memory. Digits implements
+// both Writable and Printable, so it can be used without the
+// github.com/keep94/sqrt package, for example in tests and examples.
+type Digits struct {
+	digits []int
+}
+
+// FromString returns the digits in s. s may contain only '0' through '9'.
+// If s contains any other character, FromString returns an error giving
+// its index. Use FromStringRadix for digits in other bases.
+func FromString(s string) (Digits, error) {
+	return FromStringRadix(s, 10)
+}
+
+// FromStringRadix works like FromString except that s holds digits in
+// base radix. The letters 'a' through 'z' stand for the digits 10 through
+// 35, and upper case letters work the same as lower case letters. Each
+// digit must be less than radix. radix must be between 2 and 36.
+func FromStringRadix(s string, radix int) (Digits, error) {
+	if radix < 2 || radix > len(digitChars) {
+		return Digits{}, errBadRadix
+	}
+	digits := make([]int, len(s))
+	for i := 0; i < len(s); i++ {
+		digit, ok := parseDigit(s[i])
+		if !ok || digit >= radix {
+			return Digits{}, fmt.Errorf(
+				"numprint: invalid digit %q at index %d", s[i], i)
+		}
+		digits[i] = digit
+	}
+	return Digits{digits: digits}, nil
+}
+
+// FromDigits returns a Digits containing the values in digits. FromDigits
+// copies digits. Each value must be between 0 and 9. If one is not,
+// FromDigits returns an error giving its index. Use FromDigitsRadix for
+// digits in other bases.
+func FromDigits(digits []int) (Digits, error) {
+	return FromDigitsRadix(digits, 10)
+}
+
+// FromDigitsRadix works like FromDigits except that each value must be
+// between 0 and radix-1. radix must be between 2 and 36.
+func FromDigitsRadix(digits []int, radix int) (Digits, error) {
+	result := append([]int(nil), digits...)
+	if err := checkDigits(result, radix); err != nil {
+		return Digits{}, err
+	}
+	return Digits{digits: result}, nil
+}
+
+// FromBytes works like FromDigits except that it takes the values of the
+// digits as bytes. The bytes are digit values such as 3, 1, 4, not
+// characters such as '3', '1', '4'. Use FromString for characters.
+func FromBytes(digits []byte) (Digits, error) {
+	result := make([]int, len(digits))
+	for i, digit := range digits {
+		result[i] = int(digit)
+	}
+	if err := checkDigits(result, 10); err != nil {
+		return Digits{}, err
+	}
+	return Digits{digits: result}, nil
+}
+
+// All returns the 0 based position and value of each digit in d from
+// beginning to end.
+func (d Digits) All() iter.Seq2[int, int] {
+	return d.AllInRange(0, len(d.digits))
+}
+
+// Backward returns the 0 based position and value of each digit in d from
+// end to beginning.
+func (d Digits) Backward() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for i := len(d.digits) - 1; i >= 0; i-- {
+			if !yield(i, d.digits[i]) {
+				return
+			}
+		}
+	}
+}
+
+// AllInRange returns the 0 based position and value of each digit in d from
+// position start up to but not including position end. AllInRange ignores
+// positions that are out of range for d.
+func (d Digits) AllInRange(start, end int) iter.Seq2[int, int] {
+	start = max(start, 0)
+	end = min(end, len(d.digits))
+	return func(yield func(int, int) bool) {
+		for i := start; i < end; i++ {
+			if !yield(i, d.digits[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Len returns the number of digits in d.
+func (d Digits) Len() int {
+	return len(d.digits)
+}
+
+func parseDigit(ch byte) (int, bool) {
+	switch {
+	case ch >= '0' && ch <= '9':
+		return int(ch - '0'), true
+	case ch >= 'a' && ch <= 'z':
+		return int(ch-'a') + 10, true
+	case ch >= 'A' && ch <= 'Z':
+		return int(ch-'A') + 10, true
+	}
+	return 0, false
+}
+
+func checkDigits(digits []int, radix int) error {
+	if radix < 2 || radix > len(digitChars) {
+		return errBadRadix
+	}
+	for i, digit := range digits {
+		if digit < 0 || digit >= radix {
+			return fmt.Errorf(
+				"numprint: digit %d at index %d out of range for radix %d",
+				digit, i, radix)
+		}
+	}
+	return nil
+}
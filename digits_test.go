@@ -0,0 +1,98 @@
+package numprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromString(t *testing.T) {
+	digits, err := FromString("314159")
+	assert.NoError(t, err)
+	assert.Equal(t, 6, digits.Len())
+	assert.Equal(t, "31415 9\n", Swrite(digits, ShowCount(false)))
+	assert.Equal(
+		t, "95141 3\n", Swrite(digits, ShowCount(false), Reverse(true)))
+	assert.Equal(
+		t, "0...415 9", Sprint(digits, Between(2, 100), ShowCount(false)))
+}
+
+func TestFromStringRadix(t *testing.T) {
+	_, err := FromString("fF0a")
+	assert.EqualError(t, err, `numprint: invalid digit 'f' at index 0`)
+	_, err = FromStringRadix("fF0g", 16)
+	assert.EqualError(t, err, `numprint: invalid digit 'g' at index 3`)
+	_, err = FromStringRadix("10", 1)
+	assert.Error(t, err)
+	digits, err := FromStringRadix("fF0a", 16)
+	assert.NoError(t, err)
+	assert.Equal(t, "ff0a\n", Swrite(digits, ShowCount(false), Radix(16)))
+	assert.Equal(
+		t,
+		"FF0A\n",
+		Swrite(digits, ShowCount(false), Radix(16), UpperCase(true)))
+	assert.Equal(
+		t,
+		"FF零A\n",
+		Swrite(
+			digits, ShowCount(false), Radix(16), UpperCase(true),
+			CustomDigits([10]rune{
+				'零', '一', '二', '三', '四', '五', '六', '七', '八', '九'})))
+}
+
+func TestFromStringEmpty(t *testing.T) {
+	digits, err := FromString("")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, digits.Len())
+	assert.Equal(t, 0, Len(digits))
+}
+
+func TestFromStringError(t *testing.T) {
+	_, err := FromString("3.14")
+	assert.EqualError(t, err, `numprint: invalid digit '.' at index 1`)
+}
+
+func TestDigitsEarlyStop(t *testing.T) {
+	digits, _ := FromString("123456")
+	for range digits.All() {
+		break
+	}
+	for range digits.Backward() {
+		break
+	}
+}
+
+func TestFromDigits(t *testing.T) {
+	values := []int{2, 7, 1, 8, 2, 8}
+	digits, err := FromDigits(values)
+	assert.NoError(t, err)
+	values[0] = 9
+	assert.Equal(t, "27182 8\n", Swrite(digits, ShowCount(false)))
+	assert.Equal(t, "0...182", Sprint(digits, Between(2, 5), ShowCount(false)))
+	assert.Equal(t, "", Sprint(digits, Between(10, 20), LeadingDecimal(false)))
+}
+
+func TestFromDigitsOutOfRange(t *testing.T) {
+	_, err := FromDigits([]int{1, 12, 3})
+	assert.EqualError(
+		t, err, "numprint: digit 12 at index 1 out of range for radix 10")
+	_, err = FromDigits([]int{1, -1})
+	assert.Error(t, err)
+	digits, err := FromDigitsRadix([]int{1, 12, 3}, 13)
+	assert.NoError(t, err)
+	assert.Equal(t, "1c3\n", Swrite(digits, ShowCount(false), Radix(13)))
+	_, err = FromDigitsRadix([]int{1, 13}, 13)
+	assert.Error(t, err)
+	_, err = FromDigitsRadix([]int{1}, 37)
+	assert.Error(t, err)
+}
+
+func TestFromBytes(t *testing.T) {
+	digits, err := FromBytes([]byte{1, 4, 1, 4, 2})
+	assert.NoError(t, err)
+	assert.Equal(t, "14142\n", Swrite(digits, ShowCount(false)))
+	assert.Equal(t, 5, Len(digits))
+	_, err = FromBytes([]byte{1, '4'})
+	assert.EqualError(
+		t, err, "numprint: digit 52 at index 1 out of range for radix 10")
+}
@@ -0,0 +1,48 @@
+package numprint
+
+import (
+	"bytes"
+	"io"
+)
+
+// Document is digits to write along with the options to write them with.
+// Document implements io.WriterTo so that io.Copy writes the digits
+// straight to the destination without building the output in memory
+// first.
+type Document struct {
+	s       Writable
+	options []Option
+	reader  *bytes.Reader
+	err     error
+}
+
+// NewDocument returns a Document that writes the digits of s the same way
+// Fwrite does with the same options.
+func NewDocument(s Writable, options ...Option) *Document {
+	return &Document{s: s, options: options}
+}
+
+// WriteTo writes the digits to w. WriteTo returns the number of bytes
+// written and any error encountered.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	written, err := Fwrite(w, d.s, d.options...)
+	return int64(written), err
+}
+
+// Read reads the formatted digits into p. Read is here so that Document
+// can be passed to io.Copy. Unlike WriteTo, the first call to Read formats
+// all the digits into memory. If formatting the digits fails, Read returns
+// what was formatted before the error and then the error in place of
+// io.EOF.
+func (d *Document) Read(p []byte) (int, error) {
+	if d.reader == nil {
+		var buffer bytes.Buffer
+		_, d.err = Fwrite(&buffer, d.s, d.options...)
+		d.reader = bytes.NewReader(buffer.Bytes())
+	}
+	n, err := d.reader.Read(p)
+	if err == io.EOF && d.err != nil {
+		err = d.err
+	}
+	return n, err
+}
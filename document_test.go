@@ -0,0 +1,43 @@
+package numprint
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocument(t *testing.T) {
+	number := newFakeNumberRange(0, 60)
+	document := NewDocument(number, DigitsPerRow(20))
+	var buffer bytes.Buffer
+	n, err := io.Copy(&buffer, document)
+	assert.NoError(t, err)
+	expected := Swrite(number, DigitsPerRow(20))
+	assert.Equal(t, expected, buffer.String())
+	assert.Equal(t, int64(len(expected)), n)
+	written, err := Fwrite(io.Discard, number, DigitsPerRow(20))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(written), n)
+}
+
+func TestDocumentRead(t *testing.T) {
+	number := newFakeNumberRange(0, 60)
+	document := NewDocument(number, DigitsPerRow(20))
+	actual, err := io.ReadAll(document)
+	assert.NoError(t, err)
+	assert.Equal(t, Swrite(number, DigitsPerRow(20)), string(actual))
+}
+
+func TestDocumentError(t *testing.T) {
+	document := NewDocument(newFakeNumberRange(0, 60))
+	w := &maxBytesWriter{maxBytes: 10}
+	n, err := document.WriteTo(w)
+	assert.Error(t, err)
+	assert.Equal(t, int64(10), n)
+	document = NewDocument(fakeDigits{1, 2, 11, 3}, ShowCount(false))
+	actual, err := io.ReadAll(document)
+	assert.ErrorContains(t, err, "digit 11 at position 2")
+	assert.Equal(t, "12", string(actual))
+}
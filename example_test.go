@@ -91,3 +91,16 @@ func ExamplePositions() {
 	// {Start:0 End:10}
 	// {Start:40 End:50}
 }
+
+func ExampleFromString() {
+	digits, err := numprint.FromString("31415926535897932384626")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	numprint.Write(digits, numprint.DigitsPerRow(10))
+	// Output:
+	//  0  31415 92653
+	// 10  58979 32384
+	// 20  626
+}
@@ -0,0 +1,55 @@
+package numprint
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// defaultFormatter prints and writes when callers give no options so that
+// they don't pay for building settings and buffers on each call.
+var defaultFormatter = NewFormatter()
+
+// Formatter prints and writes digits using the same options each time.
+// A Formatter works out its settings once and reuses its buffers, so
+// printing many small sequences with one Formatter costs less than calling
+// Fprint or Fwrite with the same options each time. A Formatter is safe to
+// use from multiple goroutines at once as long as its options are, but
+// the Checksum and OnRow options share state across calls, so calls using
+// them must be synchronized.
+type Formatter struct {
+	printSettings *printerSettings
+	writeSettings *printerSettings
+	writers       sync.Pool
+}
+
+// NewFormatter returns a Formatter that uses options. Fprint uses the same
+// defaults as the package level Fprint, and Fwrite uses the same defaults
+// as the package level Fwrite.
+func NewFormatter(options ...Option) *Formatter {
+	result := &Formatter{
+		printSettings: printSettings(options),
+		writeSettings: writeSettings(options),
+	}
+	result.printSettings.reverse = false
+	result.printSettings.writers = &result.writers
+	result.writeSettings.writers = &result.writers
+	result.writers.New = func() any {
+		return result.printSettings.newBufioWriter(nil)
+	}
+	return result
+}
+
+// Fprint works like the package level Fprint using the options of this
+// Formatter.
+func (f *Formatter) Fprint(w io.Writer, s Printable, p Positions) (
+	written int, err error) {
+	return fprint(context.Background(), w, s, p, f.printSettings)
+}
+
+// Fwrite works like the package level Fwrite using the options of this
+// Formatter.
+func (f *Formatter) Fwrite(w io.Writer, s Writable) (
+	written int, err error) {
+	return fwrite(context.Background(), w, s, endOf(s), f.writeSettings)
+}
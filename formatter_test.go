@@ -0,0 +1,57 @@
+package numprint
+
+import (
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatter(t *testing.T) {
+	options := []Option{DigitsPerRow(20), DigitsPerColumn(4)}
+	formatter := NewFormatter(options...)
+	number := newFakeNumberRange(0, 75)
+	for range 3 {
+		var sb strings.Builder
+		n, err := formatter.Fprint(&sb, number, Between(3, 70))
+		assert.NoError(t, err)
+		assert.Equal(t, Sprint(number, Between(3, 70), options...), sb.String())
+		assert.Equal(t, sb.Len(), n)
+		sb.Reset()
+		n, err = formatter.Fwrite(&sb, number)
+		assert.NoError(t, err)
+		assert.Equal(t, Swrite(number, options...), sb.String())
+		assert.Equal(t, sb.Len(), n)
+	}
+}
+
+func TestFormatterConcurrent(t *testing.T) {
+	formatter := NewFormatter(DigitsPerRow(10), bufferSize(16))
+	number := newFakeNumberRange(0, 100)
+	expected := Swrite(number, DigitsPerRow(10))
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 20 {
+				var sb strings.Builder
+				formatter.Fwrite(&sb, number)
+				assert.Equal(t, expected, sb.String())
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestFormatterError(t *testing.T) {
+	formatter := NewFormatter()
+	number := newFakeNumberRange(0, 100)
+	_, err := formatter.Fwrite(&maxBytesWriter{maxBytes: 10}, number)
+	assert.Error(t, err)
+	var sb strings.Builder
+	_, err = formatter.Fwrite(&sb, number)
+	assert.NoError(t, err)
+	assert.Equal(t, Swrite(number), sb.String())
+}
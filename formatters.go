@@ -2,74 +2,455 @@ package numprint
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"hash"
 	"io"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
+const (
+	digitChars      = "0123456789abcdefghijklmnopqrstuvwxyz"
+	upperDigitChars = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+
+	bitsPerNibble = 4
+	bitsPerByte   = 8
+
+	// overline is the combining character that marks repeating digits.
+	overline = "\u0305"
+)
+
+var (
+	errBadRadix = errors.New("numprint: radix must be between 2 and 36")
+)
+
+type digitSet struct {
+	customDigits []rune
+	chars        string
+	radix        int
+}
+
+func (d *digitSet) Init(settings *printerSettings) error {
+	*d = digitSet{
+		customDigits: settings.customDigits,
+		chars:        digitChars,
+		radix:        settings.radix,
+	}
+	if settings.upperCase {
+		d.chars = upperDigitChars
+	}
+	if d.radix < 2 || d.radix > len(digitChars) {
+		return errBadRadix
+	}
+	return nil
+}
+
+func (d *digitSet) Check(posit, digit int) error {
+	if digit < 0 || digit >= d.radix {
+		return fmt.Errorf(
+			"numprint: digit %d at position %d out of range for radix %d",
+			digit, posit, d.radix)
+	}
+	return nil
+}
+
+func (d *digitSet) Rune(digit int) rune {
+	if digit < len(d.customDigits) {
+		return d.customDigits[digit]
+	}
+	return rune(d.chars[digit])
+}
+
 type printer struct {
 	rawPrinter
-	missingDigit rune
+	missing   string
+	missingFn func(posit int) rune
+	verbose   bool
+	digits    digitSet
+	highlight map[int]string
+	skipGaps  bool
+	strict    bool
+	rendered  int
+
+	// When CollapseGaps is on, gapEnd is the position right after the
+	// last digit written.
+	gapEnd int
+
+	// When highlightLast is set, the last digit consumed waits in
+	// pendingPosit and pendingDigit until the next digit comes or printing
+	// finishes.
+	highlightLast string
+	pending       bool
+	pendingPosit  int
+	pendingDigit  int
+
+	checksum      hash.Hash
+	checksumBytes [1]byte
+	checksumLine  bool
+	onDigit       func(posit, digit int)
+
+	onProgress       func(done, total int)
+	progressInterval int
+	progressCount    int
+
+	// Digits at positions from repeatStart up to repeatEnd get an
+	// overline.
+	repeatStart int
+	repeatEnd   int
+
+	// limited goes true when a digit comes after limit digits have been
+	// consumed.
+	limit    int
+	consumed int
+	limited  bool
 }
 
 func newPrinter(
 	writer io.Writer, maxDigits int, settings *printerSettings) *printer {
+	settings = settings.withLayout(maxDigits)
 	var result printer
 	result.Init(writer, maxDigits, settings)
-	result.missingDigit = settings.missingDigit
+	result.missing = settings.missingText()
+	result.missingFn = settings.missingDigitFunc
+	result.verbose = settings.verbose
+	if err := result.digits.Init(settings); err != nil {
+		result.err = err
+	}
+	if _, ok := settings.rowStarterFits(maxDigits); !ok && result.err == nil {
+		result.err = fmt.Errorf(
+			"numprint: integer part does not fit before column %d",
+			settings.decimalColumn)
+	}
+	if settings.lineTooLong && result.err == nil {
+		result.err = fmt.Errorf(
+			"%w: a digit does not fit in %d bytes",
+			ErrLineTooLong, settings.maxLineBytes)
+	}
+	switch settings.gapMode {
+	case GapZero:
+		result.missing = result.digitPadding + string(result.digits.Rune(0))
+		result.missingFn = nil
+	case GapSkip:
+		result.skipGaps = true
+
+		// Counts are of rendered digits which always go up.
+		result.reverse = false
+	}
+	if settings.collapseGaps {
+		result.missing = settings.missingText()
+		result.skipGaps = true
+		result.collapseGaps = true
+		result.reverse = false
+	}
+	if !settings.noColor {
+		result.highlight = settings.highlight
+		result.highlightLast = settings.highlightLast
+	}
+	result.checksum = settings.checksum
+	result.checksumLine = settings.appendChecksumLine
+	result.onDigit = settings.onDigit
+	result.strict = settings.strict
+	result.onProgress = settings.onProgress
+	result.progressInterval = settings.progressInterval
+	result.limit = settings.limit
+	result.repeatStart = settings.repeatStart
+	result.repeatEnd = settings.repeatEnd
 	return &result
 }
 
+// CanConsume works like rawPrinter.CanConsume except that it also returns
+// false once the printer has consumed its limit of digits or rows.
+func (p *printer) CanConsume() bool {
+	return p.rawPrinter.CanConsume() && !p.limited && !p.rowsCut
+}
+
+// truncated returns true if Limit or MaxRows cut printing short.
+func (p *printer) truncated() bool {
+	return p.limited || p.rowsCut
+}
+
 func (p *printer) Consume(posit, digit int) {
-	if p.index < posit {
+	if !p.CanConsume() {
+		return
+	}
+	if p.limit > 0 {
+		if p.consumed == p.limit {
+			p.limited = true
+			return
+		}
+		p.consumed++
+	}
+	if p.err = p.digits.Check(posit, digit); p.err != nil {
+		return
+	}
+	if p.checksum != nil {
+		p.checksumBytes[0] = byte(digit)
+		p.checksum.Write(p.checksumBytes[:])
+	}
+	if p.onDigit != nil {
+		p.onDigit(posit, digit)
+	}
+	if p.highlightLast == "" {
+		p.write(posit, digit, p.highlight[posit])
+	} else {
+		if p.pending {
+			p.write(
+				p.pendingPosit, p.pendingDigit, p.highlight[p.pendingPosit])
+		}
+		p.pending, p.pendingPosit, p.pendingDigit = true, posit, digit
+	}
+	if p.onProgress != nil {
+		p.reportProgress(posit)
+	}
+}
+
+// reportProgress calls the OnProgress callback if posit, the position of
+// the digit just consumed, completes another interval.
+func (p *printer) reportProgress(posit int) {
+	p.progressCount++
+	if p.progressInterval <= 0 || p.progressCount%p.progressInterval != 0 {
+		return
+	}
+	done := posit + 1
+	if p.reverse {
+		done = p.maxDigits - posit
+	}
+	p.onProgress(done, p.maxDigits)
+}
+
+// Append works like rawPrinter.Append except that it first writes any
+// pending last digit.
+func (p *printer) Append(text string) {
+	p.writePending()
+	p.rawPrinter.Append(text)
+}
+
+// Finish works like rawPrinter.Finish except that it first writes any
+// pending last digit.
+func (p *printer) Finish() {
+	p.writePending()
+	p.rawPrinter.Finish()
+	if p.checksumLine && p.checksum != nil {
+		p.writeChecksumLine()
+	}
+	if p.onProgress != nil && p.err == nil {
+		p.onProgress(p.maxDigits, p.maxDigits)
+	}
+}
+
+// writeChecksumLine writes the hex digest of the checksum on its own line
+// after everything else.
+func (p *printer) writeChecksumLine() {
+	if p.err != nil {
+		return
+	}
+	var line strings.Builder
+	if p.BytesWritten() > 0 && !p.trailingLineFeed {
+		line.WriteString(p.lineEnding)
+	}
+	line.WriteString(p.indent)
+	line.WriteString("# checksum: ")
+	line.WriteString(hex.EncodeToString(p.checksum.Sum(nil)))
+	if p.trailingLineFeed {
+		line.WriteString(p.lineEnding)
+	}
+	_, p.err = p.cWriter.WriteString(line.String())
+}
+
+// writePending writes the pending last digit highlighted.
+func (p *printer) writePending() {
+	if p.pending && p.rawPrinter.CanConsume() {
+		p.write(p.pendingPosit, p.pendingDigit, p.highlightLast)
+	}
+	p.pending = false
+}
+
+// write writes the digit at posit with the ANSI SGR parameters in sgr.
+// An empty sgr means no highlighting.
+func (p *printer) write(posit, digit int, sgr string) {
+	index := posit
+	if p.collapseGaps {
+		if posit > p.gapEnd {
+			p.gapMarker = p.missing
+		}
+		p.gapEnd = posit + 1
+		p.nextPosit = posit
+	}
+	if p.skipGaps {
+		index = p.rendered
+		p.rendered++
+	} else if p.reverse {
+		index = p.maxDigits - 1 - posit
+	}
+	index, ok := p.windowIndex(index)
+	if !ok {
+		return
+	}
+	if p.index < index {
+		if p.strict {
+			p.missingDigitError()
+			return
+		}
 		if p.digitsPerRow > 0 && p.rowStarter.CountOn() {
-			p.skipRowsFor(posit)
+			p.skipRowsFor(index)
 		}
-		for p.index < posit {
-			p.rawPrinter.Consume(p.missingDigit)
+		for p.CanConsume() && p.index < index {
+			p.writeMissing()
 		}
+		if !p.CanConsume() {
+			return
+		}
+	}
+	if posit >= p.repeatStart && posit < p.repeatEnd {
+		p.writeRepeating(posit, p.digits.Rune(digit), sgr)
+	} else if p.verbose {
+		p.writeVerbose(posit, string(p.digits.Rune(digit)), sgr)
+	} else if sgr != "" {
+		p.rawPrinter.ConsumeStyled(p.digits.Rune(digit), sgr)
+	} else {
+		p.rawPrinter.Consume(p.digits.Rune(digit))
+	}
+}
+
+// writeMissing writes the missing digit at the current index.
+func (p *printer) writeMissing() {
+	if p.verbose {
+		missing := p.missing
+		if p.missingFn != nil {
+			missing = string(p.missingFn(p.countAt(p.index)))
+		}
+		p.writeVerbose(p.countAt(p.index), missing, "")
+	} else if p.missingFn != nil {
+		p.rawPrinter.Consume(p.missingFn(p.countAt(p.index)))
+	} else {
+		p.rawPrinter.ConsumeText(p.missing)
+	}
+}
+
+// writeVerbose writes digit as posit:digit with the ANSI SGR parameters
+// in sgr.
+func (p *printer) writeVerbose(posit int, digit string, sgr string) {
+	text := strconv.Itoa(posit) + ":" + digit
+	if sgr != "" {
+		text = "\x1b[" + sgr + "m" + text + "\x1b[0m"
+	}
+	p.rawPrinter.ConsumeText(text)
+}
+
+// writeRepeating writes digit at posit with an overline to show that it
+// is part of the repeating digits.
+func (p *printer) writeRepeating(posit int, digit rune, sgr string) {
+	text := string(digit) + overline
+	if p.verbose {
+		p.writeVerbose(posit, text, sgr)
+		return
 	}
-	p.rawPrinter.Consume('0' + rune(digit))
+	if sgr != "" {
+		text = "\x1b[" + sgr + "m" + text + "\x1b[0m"
+	}
+	p.rawPrinter.ConsumeText(p.digitPadding + text)
+}
+
+// missingDigitError records that the digit at the current index is
+// missing.
+func (p *printer) missingDigitError() {
+	p.err = fmt.Errorf(
+		"%w at position %d", ErrMissingDigit, p.countAt(p.index))
 }
 
-func (p *printer) skipRowsFor(nextPosit int) {
-	currentRow := p.index / p.digitsPerRow
-	nextRow := nextPosit / p.digitsPerRow
-	if p.index%p.digitsPerRow == 0 {
-		p.skipRows(nextRow - currentRow)
-	} else if nextRow > currentRow {
-		p.skipRows(nextRow - currentRow - 1)
+// skipRowsFor skips the rows of missing digits that come before the row
+// with the digit at index. If the current row is partly written and index
+// is past it, skipRowsFor first fills out the current row with missing
+// digits so that they get their real positions.
+func (p *printer) skipRowsFor(index int) {
+	if p.index%p.digitsPerRow != 0 {
+		rowEnd := (p.index/p.digitsPerRow + 1) * p.digitsPerRow
+		if index < rowEnd {
+			return
+		}
+		for p.CanConsume() && p.index < rowEnd {
+			p.writeMissing()
+		}
+		if !p.CanConsume() {
+			return
+		}
 	}
+	p.skipRows(index/p.digitsPerRow - p.index/p.digitsPerRow)
 }
 
 type rowStarter interface {
-	Start(w *bufio.Writer, index int) error
+	Start(w textWriter, index, count int) error
 	CountOn() bool
+
+	// Width returns the width of the left margin in runes.
+	Width() int
 }
 
 type countOnStarter struct {
-	zeroString    string
-	nonZeroString string
+	zeroString string
+	width      int
+	separator  string
+	leftAlign  bool
+
+	// When skipFirst is set, the first row gets blank space in place of
+	// its count.
+	skipFirst bool
+	started   bool
+	format    func(dst []byte, count int) []byte
+	scratch   []byte
 }
 
-func (c *countOnStarter) Start(w *bufio.Writer, index int) error {
-	if index == 0 {
+func (c *countOnStarter) Start(w textWriter, index, count int) error {
+	if index == 0 && c.zeroString != "" {
+		c.started = true
 		_, err := w.WriteString(c.zeroString)
 		return err
 	}
-	_, err := fmt.Fprintf(w, c.nonZeroString, index)
+	if c.skipFirst && !c.started {
+		c.started = true
+		if err := writeSpaces(w, c.width); err != nil {
+			return err
+		}
+		_, err := w.WriteString(c.separator)
+		return err
+	}
+	c.started = true
+	c.scratch = c.format(c.scratch[:0], count)
+	padding := max(c.width-utf8.RuneCount(c.scratch), 0)
+	if !c.leftAlign {
+		if err := writeSpaces(w, padding); err != nil {
+			return err
+		}
+	}
+	if _, err := w.Write(c.scratch); err != nil {
+		return err
+	}
+	if c.leftAlign {
+		if err := writeSpaces(w, padding); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString(c.separator)
 	return err
 }
 
 func (c *countOnStarter) CountOn() bool { return true }
 
+func (c *countOnStarter) Width() int {
+	return c.width + utf8.RuneCountInString(c.separator)
+}
+
 type countOffStarter struct {
 	zeroString    string
 	nonZeroString string
 }
 
-func (c *countOffStarter) Start(w *bufio.Writer, index int) error {
+func (c *countOffStarter) Start(w textWriter, index, count int) error {
 	if index == 0 {
 		_, err := w.WriteString(c.zeroString)
 		return err
@@ -80,34 +461,230 @@ func (c *countOffStarter) Start(w *bufio.Writer, index int) error {
 
 func (c *countOffStarter) CountOn() bool { return false }
 
+func (c *countOffStarter) Width() int {
+	return utf8.RuneCountInString(c.nonZeroString)
+}
+
+// prefixStarter adds a prefix, blank space, and a sign after the left
+// margin of another rowStarter. Only the first row gets the sign. The
+// other rows get blank space in its place.
+type prefixStarter struct {
+	rowStarter
+	prefix string
+	indent int
+	sign   string
+}
+
+func (c *prefixStarter) Start(w textWriter, index, count int) error {
+	if err := c.rowStarter.Start(w, index, count); err != nil {
+		return err
+	}
+	if _, err := w.WriteString(c.prefix); err != nil {
+		return err
+	}
+	if err := writeSpaces(w, c.indent); err != nil {
+		return err
+	}
+	if index == 0 {
+		_, err := w.WriteString(c.sign)
+		return err
+	}
+	return writeSpaces(w, len(c.sign))
+}
+
+func (c *prefixStarter) Width() int {
+	return c.rowStarter.Width() + utf8.RuneCountInString(c.prefix) +
+		c.indent + len(c.sign)
+}
+
+// textWriter is what rawPrinter writes formatted text to.
+type textWriter interface {
+	io.Writer
+	io.StringWriter
+	io.ByteWriter
+	WriteRune(r rune) (int, error)
+}
+
+const spaces = "                                "
+
+// bufferedWriter is a textWriter that may buffer what it writes.
+type bufferedWriter interface {
+	textWriter
+	Flush() error
+}
+
+type flusher interface {
+	Flush() error
+}
+
+// directWriter is a bufferedWriter that does no buffering.
+type directWriter struct {
+	delegate io.Writer
+	scratch  [utf8.UTFMax]byte
+}
+
+func (d *directWriter) Write(p []byte) (int, error) {
+	return d.delegate.Write(p)
+}
+
+func (d *directWriter) WriteString(s string) (int, error) {
+	return io.WriteString(d.delegate, s)
+}
+
+func (d *directWriter) WriteByte(c byte) error {
+	d.scratch[0] = c
+	_, err := d.delegate.Write(d.scratch[:1])
+	return err
+}
+
+func (d *directWriter) WriteRune(r rune) (int, error) {
+	n := utf8.EncodeRune(d.scratch[:], r)
+	return d.delegate.Write(d.scratch[:n])
+}
+
+// Flush does nothing as directWriter does no buffering.
+func (d *directWriter) Flush() error {
+	return nil
+}
+
+// indentWriter writes indent at the start of each line. indentWriter
+// writes indent right before the first byte of a line, so it does not
+// indent the empty line after the final line feed.
+type indentWriter struct {
+	delegate io.Writer
+	indent   string
+	midLine  bool
+}
+
+func (w *indentWriter) Write(p []byte) (written int, err error) {
+	for len(p) > 0 {
+		if !w.midLine {
+			if _, err = io.WriteString(w.delegate, w.indent); err != nil {
+				return
+			}
+			w.midLine = true
+		}
+		end := len(p)
+		if i := bytes.IndexByte(p, '\n'); i >= 0 {
+			end = i + 1
+			w.midLine = false
+		}
+		n, err := w.delegate.Write(p[:end])
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[end:]
+	}
+	return
+}
+
+func writeSpaces(w textWriter, count int) error {
+	for count > 0 {
+		n := min(count, len(spaces))
+		if _, err := w.WriteString(spaces[:n]); err != nil {
+			return err
+		}
+		count -= n
+	}
+	return nil
+}
+
 type rawPrinter struct {
 	cWriter          *countingWriter
-	writer           *bufio.Writer
+	writer           bufferedWriter
+	out              textWriter
+	row              strings.Builder
+	onRow            func(rowIndex int, text string)
+	ruler            string
+	pageRuler        string
+	rowsPerPage      int
+	pageSeparator    string
+	rowCount         int
+	lineEnding       string
+	started          bool
+	fence            string
+	fenceLang        string
+	fenced           *bytes.Buffer
+	target           io.Writer
+	rowIndex         int
+	rowOpen          bool
 	rowStarter       rowStarter
 	digitsPerRow     int
-	digitsPerColumn  int
+	columns          columnLayout
+	rowSeparator     string
 	trailingLineFeed bool
+	reverse          bool
+	leadingInteger   int
+	decimalSeparator rune
+	digitPadding     string
+	maxDigits        int
+	rowLength        int
+	columnOffset     int
+	columnWindow     int
+	rowPad           int
+	padText          string
+	rowSuffix        string
+	indent           string
+	padBlank         bool
 	index            int
 	indexInRow       int
 	err              error
+
+	// When collapseGaps is set, nextPosit is the real position of the
+	// next digit, which the digit count shows, and gapMarker is what
+	// goes right before the next digit to mark a gap.
+	collapseGaps bool
+	nextPosit    int
+	gapMarker    string
+
+	// rowsCut goes true when a digit would start a row after maxRows rows.
+	maxRows int
+	rowsCut bool
 }
 
 func (p *rawPrinter) Init(
 	writer io.Writer, maxDigits int, settings *printerSettings) {
 	cWriter := &countingWriter{delegate: writer}
-	var bWriter *bufio.Writer
-	if settings.bufferSize <= 0 {
-		bWriter = bufio.NewWriter(cWriter)
-	} else {
-		bWriter = bufio.NewWriterSize(cWriter, settings.bufferSize)
-	}
+	bWriter := settings.newBufferedWriter(cWriter)
 	*p = rawPrinter{
 		cWriter:          cWriter,
 		writer:           bWriter,
 		rowStarter:       settings.computeRowStarter(maxDigits),
-		digitsPerRow:     settings.digitsPerRow,
-		digitsPerColumn:  settings.digitsPerColumn,
+		digitsPerRow:     settings.visibleDigitsPerRow(),
+		columns:          settings.columnLayout(),
+		rowSeparator:     settings.rowSeparator,
 		trailingLineFeed: settings.trailingLineFeed,
+		reverse:          settings.reverse,
+		leadingInteger:   settings.leadingInteger,
+		decimalSeparator: settings.decimalSeparator,
+		digitPadding:     settings.digitPadding(),
+		maxDigits:        maxDigits,
+		rowLength:        settings.digitsPerRow,
+		columnOffset:     max(settings.columnOffset, 0),
+		columnWindow:     max(settings.columnWindow, 0),
+		onRow:            settings.onRow,
+		lineEnding:       settings.newline(),
+		rowsPerPage:      settings.rowsPerPage,
+		pageSeparator:    settings.pageSeparator,
+		rowSuffix:        settings.rowSuffix,
+		indent:           settings.indentString(),
+		maxRows:          settings.maxRows,
+	}
+	if settings.padLastRow {
+		p.padText = settings.missingText()
+		p.padBlank = settings.padWithSpaces
+	}
+	p.ruler = settings.header(maxDigits)
+	if settings.rulerEachPage {
+		p.pageRuler = p.ruler
+	}
+	if settings.markdownFence {
+		p.startFence(writer, settings)
+	}
+	p.out = p.writer
+	if p.onRow != nil {
+		p.out = &p.row
 	}
 }
 
@@ -115,34 +692,115 @@ func (p *rawPrinter) CanConsume() bool {
 	return p.err == nil
 }
 
+func (p *rawPrinter) Abort(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
 func (p *rawPrinter) Consume(digit rune) {
-	if !p.CanConsume() {
+	if !p.startDigit() {
 		return
 	}
-	if p.index == 0 {
-		p.err = p.rowStarter.Start(p.writer, 0)
-		if p.err != nil {
+	if p.digitPadding != "" {
+		if _, p.err = p.out.WriteString(p.digitPadding); p.err != nil {
 			return
 		}
+	}
+	if digit < utf8.RuneSelf {
+		p.err = p.out.WriteByte(byte(digit))
+	} else {
+		_, p.err = p.out.WriteRune(digit)
+	}
+	p.endDigit()
+}
+
+// ConsumeStyled works like Consume except that it writes digit with the
+// ANSI SGR parameters in sgr.
+func (p *rawPrinter) ConsumeStyled(digit rune, sgr string) {
+	if !p.startDigit() {
+		return
+	}
+	_, p.err = fmt.Fprintf(
+		p.out, "%s\x1b[%sm%c\x1b[0m", p.digitPadding, sgr, digit)
+	p.endDigit()
+}
+
+// ConsumeText works like Consume except that it writes text in place of
+// a digit without padding it.
+func (p *rawPrinter) ConsumeText(text string) {
+	if !p.startDigit() {
+		return
+	}
+	_, p.err = p.out.WriteString(text)
+	p.endDigit()
+}
+
+// startDigit writes what comes before the next digit. startDigit returns
+// false if the next digit should not be written because of an error.
+func (p *rawPrinter) startDigit() bool {
+	if !p.CanConsume() {
+		return false
+	}
+	if p.leadingInteger > 0 && p.realIndex(p.index) == p.leadingInteger {
+		_, p.err = p.out.WriteRune(p.decimalSeparator)
+		if p.err != nil {
+			return false
+		}
+	}
+	if p.index == 0 {
+		if !p.writeRuler() {
+			return false
+		}
+		p.startRow()
+		p.err = p.rowStarter.Start(p.out, 0, p.countAt(0))
+		if p.err != nil || !p.padRow() {
+			return false
+		}
 	} else if p.digitsPerRow > 0 && p.index%p.digitsPerRow == 0 {
-		if p.BytesWritten()+p.bytesBuffered() > 0 {
-			_, p.err = fmt.Fprintln(p.writer)
+		if p.maxRows > 0 && p.rowCount >= p.maxRows {
+			p.rowsCut = true
+			return false
+		}
+		if p.endRow(); p.err != nil {
+			return false
+		}
+		if p.started {
+			_, p.err = p.writer.WriteString(p.lineEnding)
 			if p.err != nil {
-				return
+				return false
+			}
+			_, p.err = p.writer.WriteString(p.nextRowSeparator())
+			if p.err != nil {
+				return false
 			}
 		}
-		p.err = p.rowStarter.Start(p.writer, p.index)
-		if p.err != nil {
-			return
+		if !p.writeRuler() {
+			return false
+		}
+		p.startRow()
+		p.err = p.rowStarter.Start(p.out, p.index, p.countAt(p.index))
+		if p.err != nil || !p.padRow() {
+			return false
 		}
 		p.indexInRow = 0
-	} else if p.digitsPerColumn > 0 && p.indexInRow%p.digitsPerColumn == 0 {
-		p.err = p.writer.WriteByte(' ')
+	} else if sep := p.separatorAt(p.indexInRow + p.rowPad); sep != "" {
+		_, p.err = p.out.WriteString(sep)
 		if p.err != nil {
-			return
+			return false
+		}
+	}
+	if p.gapMarker != "" {
+		_, p.err = p.out.WriteString(p.gapMarker)
+		p.gapMarker = ""
+		if p.err != nil {
+			return false
 		}
 	}
-	_, p.err = p.writer.WriteRune(digit)
+	return true
+}
+
+func (p *rawPrinter) endDigit() {
 	if p.err != nil {
 		return
 	}
@@ -150,74 +808,1165 @@ func (p *rawPrinter) Consume(digit rune) {
 	p.indexInRow++
 }
 
-func (p *rawPrinter) Finish() {
-	if p.err == nil && p.trailingLineFeed {
-		_, p.err = fmt.Fprintln(p.writer)
-	}
-	err := p.writer.Flush()
-	if p.err == nil {
-		p.err = err
+// Append writes text right after the last digit written.
+func (p *rawPrinter) Append(text string) {
+	if !p.CanConsume() {
+		return
 	}
+	p.started = true
+	_, p.err = p.out.WriteString(text)
 }
 
-func (p *rawPrinter) BytesWritten() int {
-	return p.cWriter.bytesWritten
+// separatorAt returns the separator that goes right before the digit at
+// slot in the current row.
+func (p *rawPrinter) separatorAt(slot int) string {
+	rowLength := p.digitsPerRow
+	if rowLength <= 0 {
+		rowLength = p.maxDigits
+	}
+	return p.columns.separatorAt(slot, rowLength)
 }
 
-func (p *rawPrinter) Err() error {
-	return p.err
+// padRow writes the blank space that right aligns a row that is not full.
+// padRow returns false if there was an error.
+func (p *rawPrinter) padRow() bool {
+	p.rowPad = 0
+	remaining := p.maxDigits - p.index
+	if !p.columns.alignRight || p.digitsPerRow <= 0 || remaining <= 0 ||
+		remaining >= p.digitsPerRow {
+		return true
+	}
+	p.rowPad = p.digitsPerRow - remaining
+	width, _ := p.columns.separatorsSize(1, p.rowPad+1, p.digitsPerRow)
+	width += p.rowPad * (utf8.RuneCountInString(p.digitPadding) + 1)
+	p.err = writeSpaces(p.out, width)
+	return p.err == nil
 }
 
-func (p *rawPrinter) bytesBuffered() int {
-	return p.writer.Buffered()
+// fillRow fills the rest of the current row with the pad text in place
+// of each digit. If padBlank is set, fillRow writes spaces in place of
+// the pad text and column separators instead.
+func (p *rawPrinter) fillRow() {
+	if p.err != nil || p.digitsPerRow <= 0 {
+		return
+	}
+	for slot := p.indexInRow + p.rowPad; slot < p.digitsPerRow; slot++ {
+		sep := p.separatorAt(slot)
+		if p.padBlank {
+			p.err = writeSpaces(
+				p.out,
+				utf8.RuneCountInString(sep)+
+					utf8.RuneCountInString(p.padText))
+		} else {
+			_, p.err = p.out.WriteString(sep + p.padText)
+		}
+		if p.err != nil {
+			return
+		}
+	}
 }
 
-func (p *rawPrinter) skipRows(rowsToSkip int) {
-	p.index += rowsToSkip * p.digitsPerRow
+// columnLayout says which separators go between the digits of a row.
+type columnLayout struct {
+	digitsPerColumn int
+
+	// If set, columnWidths overrides digitsPerColumn. The last width
+	// repeats.
+	columnWidths    []int
+	columnsPerGroup int
+	columnSeparator string
+	groupSeparator  string
+	alignRight      bool
 }
 
-type printerSettings struct {
-	digitsPerRow     int
-	digitsPerColumn  int
-	showCount        bool
-	missingDigit     rune
-	bufferSize       int
-	trailingLineFeed bool
-	leadingDecimal   bool
+// hasColumns returns true if the digits of a row are split into columns.
+func (c *columnLayout) hasColumns() bool {
+	return c.digitsPerColumn > 0 || len(c.columnWidths) > 0
 }
 
-func (p *printerSettings) digitCountWidth(maxDigits int) int {
-	if !p.showCount || p.digitsPerRow <= 0 {
-		return 0
+// columnAt returns true if a new column starts right before the digit at
+// slot in a row that has room for rowLength digits. columnAt also returns
+// the number of columns that come before the new one. columnAt always
+// returns false for slot 0.
+func (c *columnLayout) columnAt(slot, rowLength int) (int, bool) {
+	if slot == 0 || !c.hasColumns() {
+		return 0, false
 	}
-	if maxDigits <= p.digitsPerRow {
-		return 0
+	offset := slot
+	if c.alignRight {
+		offset = rowLength - slot
+	}
+	if len(c.columnWidths) == 0 {
+		if offset%c.digitsPerColumn != 0 {
+			return 0, false
+		}
+		return offset / c.digitsPerColumn, true
+	}
+	column := 0
+	last := len(c.columnWidths) - 1
+	for _, width := range c.columnWidths[:last] {
+		if offset <= width {
+			return column + 1, offset == width
+		}
+		offset -= width
+		column++
+	}
+	if offset%c.columnWidths[last] != 0 {
+		return 0, false
+	}
+	return column + offset/c.columnWidths[last], true
+}
+
+// separatorAt returns the separator that goes right before the digit at
+// slot in a row that has room for rowLength digits. separatorAt returns
+// the empty string if no separator goes there.
+func (c *columnLayout) separatorAt(slot, rowLength int) string {
+	column, ok := c.columnAt(slot, rowLength)
+	if !ok {
+		return ""
+	}
+	if c.columnsPerGroup > 0 && column%c.columnsPerGroup == 0 {
+		return c.groupSeparator
+	}
+	return c.columnSeparator
+}
+
+// separatorsSize returns the total width in runes and size in bytes of
+// the separators that go right before the digits at the slots from start
+// up to but not including end in a row that has room for rowLength
+// digits.
+func (c *columnLayout) separatorsSize(
+	start, end, rowLength int) (width, size int) {
+	if !c.hasColumns() {
+		return 0, 0
+	}
+	for slot := max(start, 1); slot < end; slot++ {
+		sep := c.separatorAt(slot, rowLength)
+		width += utf8.RuneCountInString(sep)
+		size += len(sep)
+	}
+	return
+}
+
+// nextRowSeparator returns what goes before the next row. At the start of
+// a new page, nextRowSeparator returns the page separator and arranges
+// for the ruler to be written again if it repeats on each page.
+func (p *rawPrinter) nextRowSeparator() string {
+	if p.rowsPerPage <= 0 || p.rowCount%p.rowsPerPage != 0 {
+		return p.rowSeparator
+	}
+	p.ruler = p.pageRuler
+	return p.pageSeparator
+}
+
+// writeRuler writes the ruler and column headers if they have not been
+// written yet. writeRuler returns false if there was an error.
+func (p *rawPrinter) writeRuler() bool {
+	if p.ruler == "" {
+		return true
+	}
+	_, p.err = p.writer.WriteString(p.ruler + p.lineEnding)
+	p.ruler = ""
+	return p.err == nil
+}
+
+// startRow marks the start of a new row.
+func (p *rawPrinter) startRow() {
+	p.started = true
+	p.rowOpen = true
+	p.rowCount++
+	p.rowIndex = 0
+	if p.digitsPerRow > 0 {
+		p.rowIndex = p.index / p.digitsPerRow
+	}
+}
+
+// endRow writes the RowSuffix, if any, to close the current row. Then it
+// passes the row to the OnRow callback, if any, and writes it.
+func (p *rawPrinter) endRow() {
+	if !p.rowOpen {
+		return
+	}
+	p.rowOpen = false
+	if p.rowSuffix != "" && p.err == nil {
+		_, p.err = p.out.WriteString(p.rowSuffix)
+	}
+	if p.onRow == nil || p.err != nil {
+		return
+	}
+	text := p.row.String()
+	p.row.Reset()
+	p.onRow(p.rowIndex, text)
+	if p.err == nil {
+		_, p.err = p.writer.WriteString(text)
+	}
+}
+
+func (p *rawPrinter) Finish() {
+	if p.padText != "" && p.rowOpen {
+		p.fillRow()
+	}
+	p.endRow()
+	if p.fenced != nil {
+		p.flush()
+		p.writeFenced()
+		return
+	}
+	if p.err == nil && p.fence != "" {
+		p.endFence()
+	}
+	if p.err == nil && p.trailingLineFeed {
+		_, p.err = p.writer.WriteString(p.lineEnding)
+	}
+	p.flush()
+}
+
+// startFence starts a Markdown code fence. If what gets printed could
+// contain backticks, startFence sends it to a buffer instead so that
+// writeFenced can choose a fence long enough to enclose it.
+func (p *rawPrinter) startFence(writer io.Writer, settings *printerSettings) {
+	p.fence = "```"
+	if settings.mayContainBackticks() {
+		p.fenced = &bytes.Buffer{}
+		p.fenceLang = settings.fenceLang
+		p.target = writer
+		p.cWriter = &countingWriter{delegate: p.fenced}
+		p.writer = settings.newBufferedWriter(p.cWriter)
+		return
+	}
+	_, p.err = p.writer.WriteString(
+		p.fence + settings.fenceLang + p.lineEnding)
+}
+
+// endFence writes the closing Markdown code fence.
+func (p *rawPrinter) endFence() {
+	if p.started {
+		_, p.err = p.writer.WriteString(p.lineEnding)
+		if p.err != nil {
+			return
+		}
+	}
+	_, p.err = p.writer.WriteString(p.fence)
+}
+
+// writeFenced writes what is in the fence buffer to the real writer
+// within a fence longer than any run of backticks in the buffer.
+func (p *rawPrinter) writeFenced() {
+	p.cWriter = &countingWriter{delegate: p.target}
+	if p.err != nil {
+		return
+	}
+	content := p.fenced.Bytes()
+	fence := strings.Repeat("`", max(longestBacktickRun(content)+1, 3))
+	var result bytes.Buffer
+
+	// The content is already indented, but the fences are not.
+	result.WriteString(p.indent + fence + p.fenceLang + p.lineEnding)
+	result.Write(content)
+	if p.started {
+		result.WriteString(p.lineEnding)
+	}
+	result.WriteString(p.indent + fence)
+	if p.trailingLineFeed {
+		result.WriteString(p.lineEnding)
+	}
+	_, p.err = p.cWriter.Write(result.Bytes())
+}
+
+func longestBacktickRun(content []byte) int {
+	result, run := 0, 0
+	for _, ch := range content {
+		if ch == '`' {
+			run++
+			result = max(result, run)
+		} else {
+			run = 0
+		}
+	}
+	return result
+}
+
+func (p *rawPrinter) flush() {
+	err := p.writer.Flush()
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+func (p *rawPrinter) BytesWritten() int {
+	return p.cWriter.bytesWritten
+}
+
+func (p *rawPrinter) Err() error {
+	return p.err
+}
+
+func (p *rawPrinter) countAt(index int) int {
+	if p.collapseGaps {
+		return p.nextPosit
+	}
+	index = p.realIndex(index)
+	if p.reverse {
+		return p.maxDigits - 1 - index
+	}
+	return index
+}
+
+// windowIndex converts index, the index of a digit in the full layout, to
+// the index of the same digit among only the digits that ColumnOffset and
+// ColumnWindow leave visible. windowIndex returns false if the digit is
+// not visible.
+func (p *rawPrinter) windowIndex(index int) (int, bool) {
+	if p.columnOffset == 0 && p.columnWindow == 0 {
+		return index, true
+	}
+	row, col := 0, index
+	if p.rowLength > 0 {
+		row, col = index/p.rowLength, index%p.rowLength
+	}
+	col -= p.columnOffset
+	if col < 0 || (p.columnWindow > 0 && col >= p.columnWindow) {
+		return 0, false
+	}
+	return row*p.digitsPerRow + col, true
+}
+
+// realIndex is the inverse of windowIndex.
+func (p *rawPrinter) realIndex(index int) int {
+	if p.columnOffset == 0 && p.columnWindow == 0 {
+		return index
+	}
+	if p.digitsPerRow <= 0 {
+		return index + p.columnOffset
+	}
+	row, col := index/p.digitsPerRow, index%p.digitsPerRow
+	return row*p.rowLength + p.columnOffset + col
+}
+
+func (p *rawPrinter) skipRows(rowsToSkip int) {
+	p.index += rowsToSkip * p.digitsPerRow
+}
+
+type printerSettings struct {
+	digitsPerRow           int
+	digitsPerColumn        int
+	columnWidths           []int
+	columnSeparator        string
+	wordsPerGroup          int
+	groupSeparator         string
+	rowSeparator           string
+	showCount              bool
+	leftAlignCount         bool
+	countWidth             int
+	countFormat            func(count int) string
+	countGrouping          bool
+	countGroupingSeparator string
+	missingDigit           rune
+	missingDigits          string
+	missingDigitFunc       func(posit int) rune
+	decimalSeparator       rune
+	customDigits           []rune
+	radix                  int
+	bufferSize             int
+	trailingLineFeed       bool
+	leadingDecimal         bool
+	leadingInteger         int
+	noCSVHeader            bool
+	csvMissingDigit        bool
+	highlight              map[int]string
+	fitWidth               int
+	noColor                bool
+	reverse                bool
+	onRow                  func(rowIndex int, text string)
+	columnOffset           int
+	columnWindow           int
+	gapMode                GapMode
+	collapseGaps           bool
+	alignment              Alignment
+	strict                 bool
+	verbose                bool
+	padLastRow             bool
+	padWithSpaces          bool
+	limit                  int
+	maxRows                int
+	integerWidth           int
+	upperCase              bool
+	repeatStart            int
+	repeatEnd              int
+	noBuffer               bool
+	rowPrefix              string
+	maxLineBytes           int
+	negative               bool
+	indent                 int
+	onDigit                func(posit, digit int)
+	countRadix             int
+	countOffset            int
+	binaryGrouping         bool
+	skipFirstCount         bool
+	decimalColumn          int
+	lineTooLong            bool
+	rowSuffix              string
+	tabColumns             bool
+	showCountSet           bool
+	onProgress             func(done, total int)
+	progressInterval       int
+	truncationMarker       string
+	highlightLast          string
+	countThreshold         int
+	lineEnding             string
+	checksum               hash.Hash
+	appendChecksumLine     bool
+	ruler                  bool
+	columnHeaders          bool
+	rowsPerPage            int
+	pageSeparator          string
+	rulerEachPage          bool
+	gutter                 string
+	countFirstSourceOnly   bool
+	markdownFence          bool
+	fenceLang              string
+	writers                *sync.Pool
+}
+
+// withLayout returns a copy of these settings with the number of digits per
+// row computed for maxDigits total digits if needed. withLayout also turns
+// off the digit count if maxDigits is below the count threshold.
+func (p *printerSettings) withLayout(maxDigits int) *printerSettings {
+	result := *p
+	if maxDigits < result.countThreshold {
+		result.showCount = false
+	}
+	if result.binaryGrouping {
+		result.digitsPerColumn = bitsPerNibble
+		result.columnWidths = nil
+		result.wordsPerGroup = bitsPerByte / bitsPerNibble
+	}
+	if result.verbose {
+		result.digitsPerColumn = 1
+		result.columnWidths = nil
+		result.columnSeparator = " "
+		result.wordsPerGroup = 0
+		result.leadingDecimal = false
+		result.leadingInteger = 0
+		result.ruler = false
+		result.columnHeaders = false
+		result.fitWidth = 0
+		result.alignment = AlignLeft
+	}
+	if result.tabColumns {
+		result.columnSeparator = "\t"
+		result.groupSeparator = "\t"
+		result.showCount = result.showCount && result.showCountSet
+		result.leadingDecimal = false
+		result.ruler = false
+		result.columnHeaders = false
+		result.fitWidth = 0
+		result.alignment = AlignLeft
+	}
+	if result.fitWidth > 0 {
+		result.digitsPerRow = result.digitsPerRowToFit(maxDigits)
+	}
+	if result.maxLineBytes > 0 {
+		digitsPerRow, ok := result.digitsPerRowForBytes(maxDigits)
+		result.digitsPerRow = digitsPerRow
+		result.lineTooLong = !ok
+	}
+	if result.binaryGrouping && result.digitsPerRow > bitsPerByte {
+		result.digitsPerRow -= result.digitsPerRow % bitsPerByte
+	}
+	return &result
+}
+
+func (p *printerSettings) digitsPerRowToFit(maxDigits int) int {
+	settings := *p
+	for count := p.fitWidth; count > 1; count-- {
+		settings.digitsPerRow = count
+		if settings.rowWidth(maxDigits) <= p.fitWidth {
+			return count
+		}
+	}
+	return 1
+}
+
+// digitsPerRowForBytes returns the most digits per row, up to the
+// configured number, that keep each row within maxLineBytes bytes. If
+// there is no set number of digits per row and the single row for
+// maxDigits digits fits, digitsPerRowForBytes returns 0. It returns false
+// if not even one digit fits.
+func (p *printerSettings) digitsPerRowForBytes(maxDigits int) (int, bool) {
+	settings := *p
+	limit := settings.digitsPerRow
+	if limit <= 0 {
+		if settings.rowBytes(maxDigits, maxDigits) <= p.maxLineBytes {
+			return 0, true
+		}
+		limit = maxDigits
+	}
+	fits := func(count int) bool {
+		settings.digitsPerRow = count
+		return settings.rowBytes(maxDigits, count) <= p.maxLineBytes
+	}
+	if !fits(1) {
+		return 1, false
+	}
+	low, high := 1, limit
+	for low < high {
+		mid := low + (high-low+1)/2
+		if fits(mid) {
+			low = mid
+		} else {
+			high = mid - 1
+		}
+	}
+	return low, true
+}
+
+// rowBytes returns the most bytes a row of digits digits takes including
+// the left margin and row prefix and suffix but not including any
+// highlighting.
+func (p *printerSettings) rowBytes(maxDigits, digits int) int {
+	columns := p.columnLayout()
+	_, separators := columns.separatorsSize(1, digits, digits)
+	result := p.computeRowStarter(maxDigits).Width() +
+		len(p.rowPrefix) - utf8.RuneCountInString(p.rowPrefix) +
+		digits*p.digitSize() + separators + len(p.rowSuffix)
+
+	// The decimal separator in the left margin already counts as one
+	// byte.
+	if p.leadingInteger > 0 {
+		result += utf8.RuneLen(p.decimalSeparator)
+	} else if p.showLeadingDecimal() {
+		result += utf8.RuneLen(p.decimalSeparator) - 1
+	}
+	return result
+}
+
+// rowWidth returns the width in runes of a full row including the left
+// margin.
+func (p *printerSettings) rowWidth(maxDigits int) int {
+	columns := p.columnLayout()
+	separators, _ := columns.separatorsSize(1, p.digitsPerRow, p.digitsPerRow)
+	return p.computeRowStarter(maxDigits).Width() +
+		p.digitsPerRow*p.digitWidth() + separators
+}
+
+// mayContainBackticks returns true if what gets printed with these
+// settings could contain a backtick.
+func (p *printerSettings) mayContainBackticks() bool {
+	if p.countFormat != nil {
+		return true
+	}
+	texts := []string{
+		string(p.customDigits),
+		p.missingText(),
+		string(p.decimalSeparator),
+		p.columnSeparator,
+		p.groupSeparator,
+		p.rowSeparator,
+		p.countGroupingSeparator,
+		p.truncationMarker,
+	}
+	if !p.noColor {
+		for _, sgr := range p.highlight {
+			texts = append(texts, sgr)
+		}
+	}
+	for _, text := range texts {
+		if strings.ContainsRune(text, '`') {
+			return true
+		}
+	}
+	return false
+}
+
+// rulerLine returns the line that Ruler prints above the first row
+// without its line feed.
+func (p *printerSettings) rulerLine(maxDigits int) string {
+	offset := max(p.columnOffset, 0)
+	digits := p.visibleDigitsPerRow()
+	if digits <= 0 {
+		digits = max(maxDigits-offset, 0)
+		if p.columnWindow > 0 {
+			digits = min(digits, p.columnWindow)
+		}
+	}
+	columns := p.columnLayout()
+	at := p.computeRowStarter(maxDigits).Width()
+	var builder strings.Builder
+	width := 0
+	for i := 0; i < digits; i++ {
+		_, newColumn := columns.columnAt(i, digits)
+		at += utf8.RuneCountInString(columns.separatorAt(i, digits))
+		label := strconv.Itoa(offset + i)
+
+		// Leave out a label that would run into the one before it.
+		if (i == 0 || newColumn) && (width == 0 || at > width) {
+			builder.WriteString(strings.Repeat(" ", at-width))
+			builder.WriteString(label)
+			width = at + len(label)
+		}
+		at += p.digitWidth()
+	}
+	return builder.String()
+}
+
+// columnHeaderLine returns the line that ColumnHeaders prints above the
+// first row without its line feed.
+func (p *printerSettings) columnHeaderLine(maxDigits int) string {
+	digits := p.visibleDigitsPerRow()
+	if digits <= 0 {
+		digits = max(maxDigits-max(p.columnOffset, 0), 0)
+		if p.columnWindow > 0 {
+			digits = min(digits, p.columnWindow)
+		}
+	}
+	padding := p.digitPadding()
+	columns := p.columnLayout()
+	var builder strings.Builder
+	writeSpaces(&builder, p.computeRowStarter(maxDigits).Width())
+	offset := 0
+	for i := 0; i < digits; i++ {
+		if _, ok := columns.columnAt(i, digits); ok {
+			writeSpaces(
+				&builder,
+				utf8.RuneCountInString(columns.separatorAt(i, digits)))
+			offset = 0
+		}
+		builder.WriteString(padding)
+		builder.WriteByte(digitChars[offset%10])
+		offset++
+	}
+	return builder.String()
+}
+
+// columnLayout returns which separators go between the digits of a row.
+func (p *printerSettings) columnLayout() columnLayout {
+	return columnLayout{
+		digitsPerColumn: p.digitsPerColumn,
+		columnWidths:    p.columnWidths,
+		columnsPerGroup: p.wordsPerGroup,
+		columnSeparator: p.columnSeparator,
+		groupSeparator:  p.groupSeparator,
+		alignRight:      p.alignsRight(),
 	}
-	maxCounter := ((maxDigits - 1) / p.digitsPerRow) * p.digitsPerRow
-	return len(strconv.Itoa(maxCounter))
+}
+
+// alignsRight returns true if rows are right aligned.
+func (p *printerSettings) alignsRight() bool {
+	return p.alignment == AlignRight &&
+		p.limit <= 0 &&
+		p.columnOffset <= 0 &&
+		p.columnWindow <= 0 &&
+		!p.skipsGaps()
+}
+
+// skipsGaps returns true if missing digits take up no place in the rows.
+func (p *printerSettings) skipsGaps() bool {
+	return p.gapMode == GapSkip || p.collapseGaps
+}
+
+// header returns the lines that go above the first row without the final
+// line feed. header returns the empty string if there are no such lines.
+func (p *printerSettings) header(maxDigits int) string {
+	var lines []string
+	if p.ruler {
+		lines = append(lines, p.rulerLine(maxDigits))
+	}
+	if p.columnHeaders {
+		lines = append(lines, p.columnHeaderLine(maxDigits))
+	}
+	return strings.Join(lines, p.newline())
+}
+
+// estimateSize returns about how many bytes printing the digits at positions
+// 0 up to maxDigits takes.
+func (p *printerSettings) estimateSize(maxDigits int) int {
+	result := 0
+	newline := len(p.newline())
+	if p.trailingLineFeed {
+		result += newline
+	}
+	if p.markdownFence {
+		result += 2*len("```") + len(p.fenceLang) + newline
+	}
+	if maxDigits <= 0 {
+		return result
+	}
+	settings := p.withLayout(maxDigits)
+	digitsPerRow := settings.visibleDigitsPerRow()
+	rows, digits, lastRow := settings.rows(maxDigits)
+	if rows == 0 {
+		return result
+	}
+	if settings.markdownFence {
+		result += newline
+	}
+	result += digits * settings.digitSize()
+	result += rows * settings.computeRowStarter(maxDigits).Width()
+
+	// Width counts the runes of the row prefix, not its bytes.
+	result += rows * (len(settings.rowPrefix) -
+		utf8.RuneCountInString(settings.rowPrefix) + len(settings.rowSuffix))
+	result += (rows - 1) * (newline + len(settings.rowSeparator))
+	header := settings.header(maxDigits)
+	if header != "" {
+		result += len(header) + newline
+	}
+	if settings.rowsPerPage > 0 {
+		breaks := (rows - 1) / settings.rowsPerPage
+		result += breaks *
+			(len(settings.pageSeparator) - len(settings.rowSeparator))
+		if header != "" && settings.rulerEachPage {
+			result += breaks * (len(header) + newline)
+		}
+	}
+	columns := settings.columnLayout()
+	rowLength := digitsPerRow
+	if rowLength <= 0 {
+		rowLength = lastRow
+	}
+	_, fullRow := columns.separatorsSize(1, rowLength, rowLength)
+	result += (rows - 1) * fullRow
+	if columns.alignRight {
+
+		// The last row has blank space on the left.
+		pad := rowLength - lastRow
+		blank, _ := columns.separatorsSize(1, pad+1, rowLength)
+		_, separators := columns.separatorsSize(pad+1, rowLength, rowLength)
+		result += pad*settings.digitWidth() + blank + separators
+	} else {
+		_, separators := columns.separatorsSize(1, lastRow, rowLength)
+		result += separators
+		if settings.padLastRow && digitsPerRow > 0 {
+			result += settings.lastRowPadding(lastRow)
+		}
+	}
+	if settings.leadingInteger > 0 && settings.leadingInteger < maxDigits {
+		result += utf8.RuneLen(settings.decimalSeparator)
+	}
+	result += settings.indent * settings.lineCount(rows, header)
+	repeating := min(settings.repeatEnd, maxDigits) -
+		max(settings.repeatStart, 0)
+	result += max(repeating, 0) * len(overline)
+	if settings.verbose {
+		result += settings.labelsSize(maxDigits)
+	}
+	if !settings.noColor {
+		for posit, sgr := range settings.highlight {
+			if posit >= 0 && posit < maxDigits {
+				result += len("\x1b[m\x1b[0m") + len(sgr)
+			}
+		}
+	}
+	return result
+}
+
+// labelsSize returns the number of bytes that the "pos:" labels of
+// Verbose take when printing the digits from 0 up to maxDigits. These
+// settings must already have their layout.
+func (p *printerSettings) labelsSize(maxDigits int) int {
+	offset := max(p.columnOffset, 0)
+	if p.digitsPerRow <= 0 {
+		end := maxDigits
+		if p.columnWindow > 0 {
+			end = min(end, offset+p.columnWindow)
+		}
+		return labelsSize(offset, end)
+	}
+	visible := p.visibleDigitsPerRow()
+	if visible == p.digitsPerRow {
+		return labelsSize(0, maxDigits)
+	}
+	result := 0
+	for start := 0; start < maxDigits; start += p.digitsPerRow {
+		result += labelsSize(
+			start+offset, min(start+offset+visible, maxDigits))
+	}
+	return result
+}
+
+// labelsSize returns the number of bytes that the "pos:" labels of
+// Verbose take for the positions from start up to end.
+func labelsSize(start, end int) int {
+	result := 0
+	for width, limit := 1, 10; start < end; width, limit = width+1, limit*10 {
+		if start < limit {
+			next := min(end, limit)
+			result += (next - start) * (width + 1)
+			start = next
+		}
+	}
+	return result
+}
+
+// lineCount returns the number of lines that printing rows rows with
+// header above takes not counting the empty line after the final line
+// feed.
+func (p *printerSettings) lineCount(rows int, header string) int {
+	if p.indent <= 0 {
+		return 0
+	}
+	result := rows + (rows-1)*strings.Count(p.rowSeparator, "\n")
+	headers := 1
+	if p.rowsPerPage > 0 {
+		breaks := (rows - 1) / p.rowsPerPage
+		result += breaks * (strings.Count(p.pageSeparator, "\n") -
+			strings.Count(p.rowSeparator, "\n"))
+		if p.rulerEachPage {
+			headers += breaks
+		}
+	}
+	if header != "" {
+		result += headers * (strings.Count(header, "\n") + 1)
+	}
+	if p.markdownFence {
+		result += 2
+	}
+	return result
+}
+
+// rows returns the number of rows that printing the digits from 0 up to
+// maxDigits takes with these settings, the number of digits shown, and the
+// number of digits in the last row. These settings must already have
+// their layout.
+func (p *printerSettings) rows(maxDigits int) (rows, digits, lastRow int) {
+	offset := max(p.columnOffset, 0)
+	digitsPerRow := p.visibleDigitsPerRow()
+	if digitsPerRow > 0 {
+		fullRows := maxDigits / p.digitsPerRow
+		lastRow = min(max(maxDigits%p.digitsPerRow-offset, 0), digitsPerRow)
+		rows, digits = fullRows, fullRows*digitsPerRow+lastRow
+		if lastRow > 0 {
+			rows++
+		} else {
+			lastRow = digitsPerRow
+		}
+	} else if p.digitsPerRow <= 0 {
+		digits = max(maxDigits-offset, 0)
+		if p.columnWindow > 0 {
+			digits = min(digits, p.columnWindow)
+		}
+		lastRow = digits
+		if digits > 0 {
+			rows = 1
+		}
+	}
+	return
+}
+
+// lastRowPadding returns the number of bytes that PadLastRow adds to a
+// last row of digitCount digits.
+func (p *printerSettings) lastRowPadding(digitCount int) int {
+	digitsPerRow := p.visibleDigitsPerRow()
+	columns := p.columnLayout()
+	width, size := columns.separatorsSize(
+		digitCount, digitsPerRow, digitsPerRow)
+	pad := digitsPerRow - digitCount
+	if p.padWithSpaces {
+		return width + pad*p.digitWidth()
+	}
+	return size + pad*len(p.missingText())
+}
+
+// digitSize returns the largest number of bytes a digit takes.
+func (p *printerSettings) digitSize() int {
+	result := 1
+	for _, r := range p.customDigits {
+		result = max(result, utf8.RuneLen(r))
+	}
+	return max(len(p.digitPadding())+result, len(p.missingText()))
+}
+
+// visibleDigitsPerRow returns the number of digits per row that
+// ColumnOffset and ColumnWindow leave visible.
+func (p *printerSettings) visibleDigitsPerRow() int {
+	if p.digitsPerRow <= 0 {
+		return p.digitsPerRow
+	}
+	result := max(p.digitsPerRow-max(p.columnOffset, 0), 0)
+	if p.columnWindow > 0 {
+		result = min(result, p.columnWindow)
+	}
+	return result
+}
+
+// newBufferedWriter returns a buffered writer that writes to w. If the
+// writer that w counts bytes for already has a Flush method or if the
+// NoBuffer option is on, newBufferedWriter returns a writer that writes
+// to w directly. If the Indent option is on, the returned writer indents
+// each line it writes.
+func (p *printerSettings) newBufferedWriter(
+	w *countingWriter) bufferedWriter {
+	var target io.Writer = w
+	if p.indent > 0 {
+		target = &indentWriter{delegate: w, indent: p.indentString()}
+	}
+	if _, ok := w.delegate.(flusher); ok || p.noBuffer {
+		return &directWriter{delegate: target}
+	}
+	if p.writers != nil {
+		result := p.writers.Get().(*bufio.Writer)
+		result.Reset(target)
+		return result
+	}
+	return p.newBufioWriter(target)
+}
+
+// indentString returns the blank space that goes at the start of each
+// line.
+func (p *printerSettings) indentString() string {
+	return strings.Repeat(" ", max(p.indent, 0))
+}
+
+func (p *printerSettings) newBufioWriter(w io.Writer) *bufio.Writer {
+	if p.bufferSize <= 0 {
+		return bufio.NewWriter(w)
+	}
+	return bufio.NewWriterSize(w, p.bufferSize)
+}
+
+// releaseWriter returns w to the pool of buffered writers if these
+// settings have one. Callers must not use w afterwards.
+func (p *printerSettings) releaseWriter(w bufferedWriter) {
+	if bw, ok := w.(*bufio.Writer); ok && p.writers != nil {
+		bw.Reset(nil)
+		p.writers.Put(bw)
+	}
+}
+
+// newline returns the line ending to use.
+func (p *printerSettings) newline() string {
+	if p.lineEnding == "" {
+		return "\n"
+	}
+	return p.lineEnding
+}
+
+// missingText returns what gets printed for a missing digit.
+func (p *printerSettings) missingText() string {
+	if p.missingDigits != "" {
+		return p.missingDigits
+	}
+	return string(p.missingDigitRune())
+}
+
+// digitWidth returns the width in runes of each digit. Digits are as wide
+// as the missing digit marker.
+func (p *printerSettings) digitWidth() int {
+	return max(utf8.RuneCountInString(p.missingText()), 1)
+}
+
+// digitPadding returns the spaces that go before each digit to make it as
+// wide as the missing digit marker.
+func (p *printerSettings) digitPadding() string {
+	return strings.Repeat(" ", p.digitWidth()-1)
+}
+
+func (p *printerSettings) missingDigitRune() rune {
+	if p.missingDigit != 0 {
+		return p.missingDigit
+	}
+	return p.decimalSeparator
+}
+
+func (p *printerSettings) showLeadingDecimal() bool {
+	return p.leadingDecimal && p.leadingInteger <= 0 && p.columnOffset <= 0
+}
+
+func (p *printerSettings) leadingDecimalString() string {
+	return p.sign() + "0" + string(p.decimalSeparator)
+}
+
+// sign returns the sign that goes before the first digit.
+func (p *printerSettings) sign() string {
+	if p.negative {
+		return "-"
+	}
+	return ""
+}
+
+// appendCount works like formatCount except that it appends to dst.
+func (p *printerSettings) appendCount(dst []byte, count int) []byte {
+	if p.countFormat != nil || p.countGrouping {
+		return append(dst, p.formatCount(count)...)
+	}
+	return strconv.AppendInt(dst, int64(count+p.countOffset), p.countBase())
+}
+
+// countBase returns the base of the digit count.
+func (p *printerSettings) countBase() int {
+	if p.countRadix < 2 || p.countRadix > len(digitChars) {
+		return 10
+	}
+	return p.countRadix
+}
+
+func (p *printerSettings) formatCount(count int) string {
+	count += p.countOffset
+	if p.countFormat != nil {
+		return p.countFormat(count)
+	}
+	result := strconv.FormatInt(int64(count), p.countBase())
+	if p.countGrouping {
+		sep := p.countGroupingSeparator
+		if sep == "" {
+			sep = ","
+		}
+		result = groupThousands(result, sep)
+	}
+	return result
+}
+
+func (p *printerSettings) digitCountWidth(maxDigits int) int {
+	if !p.showCount || p.digitsPerRow <= 0 {
+		return 0
+	}
+	if p.countWidth > 0 {
+		return p.countWidth
+	}
+	offset := max(p.columnOffset, 0)
+	if maxDigits <= p.digitsPerRow && offset == 0 {
+		return 0
+	}
+	maxCounter := ((maxDigits-1)/p.digitsPerRow)*p.digitsPerRow + offset
+	if p.reverse {
+		return max(
+			utf8.RuneCountInString(p.formatCount(maxDigits-1)),
+			utf8.RuneCountInString(p.formatCount(maxDigits-1-maxCounter)))
+	}
+	return max(
+		utf8.RuneCountInString(p.formatCount(offset)),
+		utf8.RuneCountInString(p.formatCount(maxCounter)))
 }
 
 func (p *printerSettings) computeRowStarter(maxDigits int) rowStarter {
+	result, _ := p.rowStarterFits(maxDigits)
+	return result
+}
+
+// rowStarterFits works like computeRowStarter except that it also returns
+// false if the integer digits are too wide for the decimal separator to
+// go at the column that DecimalColumn sets.
+func (p *printerSettings) rowStarterFits(maxDigits int) (rowStarter, bool) {
+	result := p.computeMargin(maxDigits)
+
+	// The leading decimal in the margin already has the sign.
+	var sign string
+	if !p.showLeadingDecimal() {
+		sign = p.sign()
+	}
+	indent, ok := p.integerIndent(
+		maxDigits,
+		max(p.indent, 0)+result.Width()+
+			utf8.RuneCountInString(p.rowPrefix)+len(sign))
+	if p.rowPrefix != "" || indent > 0 || sign != "" {
+		return &prefixStarter{
+			rowStarter: result,
+			prefix:     p.rowPrefix,
+			indent:     indent,
+			sign:       sign,
+		}, ok
+	}
+	return result, ok
+}
+
+// integerIndent returns the blank space to add after the left margin so
+// that the decimal separator goes at the column that DecimalColumn sets or
+// lines up as if there were integerWidth integer digits. before is the
+// width of what comes before the first digit on a line. integerIndent
+// returns false if the integer digits are too wide for DecimalColumn.
+func (p *printerSettings) integerIndent(maxDigits, before int) (int, bool) {
+	if p.leadingInteger <= 0 {
+		return 0, true
+	}
+	rowLength := p.visibleDigitsPerRow()
+	if rowLength <= 0 {
+		rowLength = maxDigits
+	}
+	rowLength = max(rowLength, p.integerWidth)
+	columns := p.columnLayout()
+	integerPartWidth := func(digits int) int {
+		width, _ := columns.separatorsSize(1, digits, rowLength)
+		return width + digits*p.digitWidth()
+	}
+	if p.decimalColumn > 0 {
+		indent := p.decimalColumn - before -
+			integerPartWidth(p.leadingInteger)
+		return max(indent, 0), indent >= 0
+	}
+	if p.integerWidth <= p.leadingInteger {
+		return 0, true
+	}
+	return integerPartWidth(p.integerWidth) -
+		integerPartWidth(p.leadingInteger), true
+}
+
+func (p *printerSettings) computeMargin(maxDigits int) rowStarter {
 	width := p.digitCountWidth(maxDigits)
 	if width <= 0 {
-		if p.leadingDecimal {
-			return &countOffStarter{zeroString: "0.", nonZeroString: "  "}
+		if p.showLeadingDecimal() {
+			zero := p.leadingDecimalString()
+			return &countOffStarter{
+				zeroString: zero,
+				nonZeroString: strings.Repeat(
+					" ", utf8.RuneCountInString(zero)),
+			}
 		} else if p.showCount {
-			return &countOffStarter{zeroString: "0  ", nonZeroString: "   "}
+			offset := max(p.columnOffset, 0)
+			zero := p.formatCount(offset)
+			if p.reverse {
+				zero = p.formatCount(maxDigits - 1 - offset)
+			}
+			blank := strings.Repeat(" ", utf8.RuneCountInString(zero))
+			if p.skipFirstCount {
+				zero = blank
+			}
+			return &countOffStarter{
+				zeroString:    zero + p.countSeparator(),
+				nonZeroString: blank + p.countSeparator(),
+			}
 		} else {
 			return &countOffStarter{}
 		}
 	}
-	if p.leadingDecimal {
-		return &countOnStarter{
-			zeroString:    strings.Repeat(" ", width) + "0.",
-			nonZeroString: fmt.Sprintf("%%%dd  ", width),
-		}
+	result := &countOnStarter{
+		width:     width,
+		separator: p.countSeparator(),
+		leftAlign: p.leftAlignCount,
+		format:    p.appendCount,
+		skipFirst: p.skipFirstCount,
 	}
-	return &countOnStarter{
-		zeroString:    strings.Repeat(" ", width-1) + "0  ",
-		nonZeroString: fmt.Sprintf("%%%dd  ", width),
+	if p.showLeadingDecimal() {
+		result.zeroString = strings.Repeat(
+			" ", width) + p.leadingDecimalString()
+		result.separator += strings.Repeat(" ", len(p.sign()))
 	}
+	return result
+}
+
+// countSeparator returns what goes between the digit count and the digits.
+func (p *printerSettings) countSeparator() string {
+	if p.tabColumns {
+		return "\t"
+	}
+	return "  "
+}
+
+func groupThousands(digits, sep string) string {
+	if len(digits) <= 3 {
+		return digits
+	}
+	var builder strings.Builder
+	first := len(digits) % 3
+	if first == 0 {
+		first = 3
+	}
+	builder.WriteString(digits[:first])
+	for i := first; i < len(digits); i += 3 {
+		builder.WriteString(sep)
+		builder.WriteString(digits[i : i+3])
+	}
+	return builder.String()
 }
 
 type countingWriter struct {
@@ -225,8 +1974,27 @@ type countingWriter struct {
 	bytesWritten int
 }
 
+// Write writes p to the delegate. Write reports io.ErrShortWrite if the
+// delegate writes less than all of p without reporting an error.
 func (c *countingWriter) Write(p []byte) (n int, err error) {
 	n, err = c.delegate.Write(p)
+	return c.count(n, len(p), err)
+}
+
+// WriteString writes s to the delegate without copying it to a []byte
+// first if the delegate has a WriteString method.
+func (c *countingWriter) WriteString(s string) (n int, err error) {
+	n, err = io.WriteString(c.delegate, s)
+	return c.count(n, len(s), err)
+}
+
+// count adds n, the number of bytes the delegate wrote out of length, to
+// the bytes written.
+func (c *countingWriter) count(n, length int, err error) (int, error) {
+	n = max(min(n, length), 0)
 	c.bytesWritten += n
-	return
+	if n < length && err == nil {
+		err = io.ErrShortWrite
+	}
+	return n, err
 }
@@ -0,0 +1,140 @@
+package numprint
+
+import (
+	"io"
+)
+
+// gridFormat renders a grid of digits where each row has an optional count
+// followed by columns of digits. StartRow starts the first column of a row;
+// EndRow ends the last column of a row.
+type gridFormat interface {
+	Start(w bufferedWriter) error
+	StartRow(w bufferedWriter, count string) error
+	NextColumn(w bufferedWriter) error
+	Digit(w bufferedWriter, digit string) error
+	EndRow(w bufferedWriter) error
+	Finish(w bufferedWriter) error
+}
+
+// gridPrinter lays out digits into rows and columns for a gridFormat. Unlike
+// printer, gridPrinter writes every missing digit instead of skipping
+// rows. gridPrinter passes each digit and count through escape before
+// giving it to the gridFormat.
+type gridPrinter struct {
+	cWriter      *countingWriter
+	writer       bufferedWriter
+	format       gridFormat
+	escape       func(string) string
+	settings     *printerSettings
+	digits       digitSet
+	missingDigit string
+	digitsPerRow int
+	columns      columnLayout
+	showCount    bool
+	index        int
+	err          error
+}
+
+func newGridPrinter(
+	writer io.Writer,
+	format gridFormat,
+	escape func(string) string,
+	settings *printerSettings) *gridPrinter {
+	cWriter := &countingWriter{delegate: writer}
+	result := &gridPrinter{
+		cWriter:      cWriter,
+		writer:       settings.newBufferedWriter(cWriter),
+		format:       format,
+		escape:       escape,
+		settings:     settings,
+		missingDigit: escape(settings.missingText()),
+		digitsPerRow: settings.digitsPerRow,
+		columns:      settings.columnLayout(),
+		showCount:    settings.showCount && settings.digitsPerRow > 0,
+	}
+	result.columns.alignRight = false
+	result.err = result.digits.Init(settings)
+	if result.err == nil {
+		result.err = format.Start(result.writer)
+	}
+	return result
+}
+
+func (g *gridPrinter) CanConsume() bool {
+	return g.err == nil
+}
+
+func (g *gridPrinter) Abort(err error) {
+	if g.err == nil {
+		g.err = err
+	}
+}
+
+func (g *gridPrinter) Consume(posit, digit int) {
+	if !g.CanConsume() {
+		return
+	}
+	if g.err = g.digits.Check(posit, digit); g.err != nil {
+		return
+	}
+	for g.index < posit && g.err == nil {
+		if fn := g.settings.missingDigitFunc; fn != nil {
+			g.consume(g.escape(string(fn(g.index))))
+		} else {
+			g.consume(g.missingDigit)
+		}
+	}
+	if g.err == nil {
+		g.consume(g.escape(string(g.digits.Rune(digit))))
+	}
+}
+
+func (g *gridPrinter) consume(digit string) {
+	indexInRow := g.index
+	if g.digitsPerRow > 0 {
+		indexInRow = g.index % g.digitsPerRow
+	}
+	if indexInRow == 0 {
+		if g.index > 0 {
+			if g.err = g.format.EndRow(g.writer); g.err != nil {
+				return
+			}
+		}
+		var count string
+		if g.showCount {
+			count = g.escape(g.settings.formatCount(g.index))
+		}
+		if g.err = g.format.StartRow(g.writer, count); g.err != nil {
+			return
+		}
+	} else if _, ok := g.columns.columnAt(indexInRow, g.digitsPerRow); ok {
+		if g.err = g.format.NextColumn(g.writer); g.err != nil {
+			return
+		}
+	}
+	if g.err = g.format.Digit(g.writer, digit); g.err != nil {
+		return
+	}
+	g.index++
+}
+
+func (g *gridPrinter) Finish() {
+	if g.err == nil && g.index > 0 {
+		g.err = g.format.EndRow(g.writer)
+	}
+	if g.err == nil {
+		g.err = g.format.Finish(g.writer)
+	}
+	err := g.writer.Flush()
+	if g.err == nil {
+		g.err = err
+	}
+}
+
+func (g *gridPrinter) BytesWritten() int {
+	return g.cWriter.bytesWritten
+}
+
+func (g *gridPrinter) Err() error {
+	return g.err
+}
@@ -0,0 +1,29 @@
+package numprint
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+)
+
+// Fgzip works like Fwrite except that it writes the digits of s to w
+// compressed with gzip. Fgzip closes the gzip stream so that w gets the
+// complete compressed data including the gzip trailer, but it does not
+// close w. Fgzip returns the number of compressed bytes written to w and
+// any error encountered including any error from closing the gzip stream.
+func Fgzip(w io.Writer, s Writable, options ...Option) (
+	written int, err error) {
+	cWriter := &countingWriter{delegate: w}
+	zWriter := gzip.NewWriter(cWriter)
+
+	// A gzip.Writer has a Flush method, so Fwrite would not buffer for it.
+	bWriter := bufio.NewWriter(zWriter)
+	_, err = Fwrite(bWriter, s, options...)
+	if flushErr := bWriter.Flush(); err == nil {
+		err = flushErr
+	}
+	if closeErr := zWriter.Close(); err == nil {
+		err = closeErr
+	}
+	return cWriter.bytesWritten, err
+}
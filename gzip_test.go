@@ -0,0 +1,38 @@
+package numprint
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFgzip(t *testing.T) {
+	number := newFakeNumberRange(0, 1000)
+	var buffer bytes.Buffer
+	written, err := Fgzip(&buffer, number, DigitsPerRow(20))
+	assert.NoError(t, err)
+	assert.Equal(t, buffer.Len(), written)
+	reader, err := gzip.NewReader(&buffer)
+	assert.NoError(t, err)
+	content, err := io.ReadAll(reader)
+	assert.NoError(t, err)
+	assert.Equal(t, Swrite(number, DigitsPerRow(20)), string(content))
+}
+
+func TestFgzipError(t *testing.T) {
+	number := newFakeNumberRange(0, 10)
+	_, err := Fgzip(errorWriter{}, number)
+	assert.Error(t, err)
+	_, err = Fgzip(io.Discard, number, Radix(1))
+	assert.Error(t, err)
+}
+
+type errorWriter struct{}
+
+func (errorWriter) Write(p []byte) (int, error) {
+	return 0, errors.New("write failed")
+}
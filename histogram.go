@@ -0,0 +1,29 @@
+package numprint
+
+// Histogram returns how many times each digit 0-9 appears in s at the
+// positions in p. result[d] is the count for digit d. Histogram does not
+// count positions for which s has no digit, and it ignores digits above 9.
+func Histogram(s Printable, p Positions) [10]int {
+	var result [10]int
+	copy(result[:], HistogramRadix(s, p, 10))
+	return result
+}
+
+// HistogramRadix works like Histogram except that it counts the digits 0
+// through radix-1 for use with the Radix option. The returned slice has
+// radix elements. HistogramRadix ignores digits outside that range.
+// HistogramRadix returns nil if radix is not positive.
+func HistogramRadix(s Printable, p Positions, radix int) []int {
+	if radix <= 0 {
+		return nil
+	}
+	result := make([]int, radix)
+	for pr := range p.All() {
+		for _, digit := range s.AllInRange(pr.Start, pr.End) {
+			if digit >= 0 && digit < radix {
+				result[digit]++
+			}
+		}
+	}
+	return result
+}
@@ -0,0 +1,30 @@
+package numprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHistogram(t *testing.T) {
+	digits, _ := FromString("31415926535")
+	assert.Equal(
+		t,
+		[10]int{0, 2, 1, 2, 1, 3, 1, 0, 0, 1},
+		Histogram(digits, UpTo(100)))
+	assert.Equal(
+		t,
+		[10]int{0, 1, 0, 0, 1, 1, 0, 0, 0, 0},
+		Histogram(digits, buildPositions(1, 3, 4, 5)))
+	assert.Equal(t, [10]int{}, Histogram(digits, Positions{}))
+}
+
+func TestHistogramRadix(t *testing.T) {
+	digits, _ := FromStringRadix("ff0a1z", 36)
+	assert.Equal(
+		t,
+		[]int{1, 1, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0, 0, 2},
+		HistogramRadix(digits, UpTo(6), 16))
+	assert.Equal(t, []int{1, 1}, HistogramRadix(digits, UpTo(6), 2))
+	assert.Nil(t, HistogramRadix(digits, UpTo(6), 0))
+}
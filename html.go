@@ -0,0 +1,63 @@
+package numprint
+
+import (
+	"context"
+	"html"
+	"io"
+)
+
+// Fhtml writes all the digits of s to w as an HTML table. Each row of
+// digits is a <tr> element, and each column of digits is a <td> element.
+// When showing the digit count, the first cell of each row is a <th>
+// element containing the count. Fhtml uses the same defaults as Fwrite,
+// but it ignores options that affect only plain text such as
+// ColumnSeparator. Fhtml returns the number of bytes written and any error
+// encountered.
+func Fhtml(w io.Writer, s Writable, options ...Option) (
+	written int, err error) {
+	printer := newGridPrinter(
+		w, htmlFormat{}, html.EscapeString, writeSettings(options))
+	fromIterator(context.Background(), s.All(), printer)
+	printer.Finish()
+	return printer.BytesWritten(), printer.Err()
+}
+
+type htmlFormat struct{}
+
+func (htmlFormat) Start(w bufferedWriter) error {
+	_, err := w.WriteString("<table>\n")
+	return err
+}
+
+func (htmlFormat) StartRow(w bufferedWriter, count string) error {
+	if _, err := w.WriteString("<tr>"); err != nil {
+		return err
+	}
+	if count != "" {
+		if _, err := w.WriteString("<th>" + count + "</th>"); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString("<td>")
+	return err
+}
+
+func (htmlFormat) NextColumn(w bufferedWriter) error {
+	_, err := w.WriteString("</td><td>")
+	return err
+}
+
+func (htmlFormat) Digit(w bufferedWriter, digit string) error {
+	_, err := w.WriteString(digit)
+	return err
+}
+
+func (htmlFormat) EndRow(w bufferedWriter) error {
+	_, err := w.WriteString("</td></tr>\n")
+	return err
+}
+
+func (htmlFormat) Finish(w bufferedWriter) error {
+	_, err := w.WriteString("</table>\n")
+	return err
+}
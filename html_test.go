@@ -0,0 +1,57 @@
+package numprint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtml(t *testing.T) {
+	var builder strings.Builder
+	n, err := Fhtml(
+		&builder,
+		newFakeNumberRange(3, 23),
+		DigitsPerRow(10),
+		DigitsPerColumn(4),
+		MissingDigit('<'))
+	assert.NoError(t, err)
+	expected := `<table>
+<tr><th>0</th><td>&lt;&lt;&lt;4</td><td>5678</td><td>90</td></tr>
+<tr><th>10</th><td>1234</td><td>5678</td><td>90</td></tr>
+<tr><th>20</th><td>123</td></tr>
+</table>
+`
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, len(expected), n)
+}
+
+func TestHtmlNoCount(t *testing.T) {
+	var builder strings.Builder
+	_, err := Fhtml(
+		&builder, newFakeNumberRange(0, 7), ShowCount(false), DigitsPerRow(5))
+	assert.NoError(t, err)
+	expected := `<table>
+<tr><td>12345</td></tr>
+<tr><td>67</td></tr>
+</table>
+`
+	assert.Equal(t, expected, builder.String())
+}
+
+func TestHtmlEmpty(t *testing.T) {
+	var builder strings.Builder
+	_, err := Fhtml(&builder, newFakeNumberRange(0, 0))
+	assert.NoError(t, err)
+	assert.Equal(t, "<table>\n</table>\n", builder.String())
+}
+
+func TestHtmlError(t *testing.T) {
+	for i := 0; i < 60; i += 7 {
+		w := &maxBytesWriter{maxBytes: i}
+		n, err := Fhtml(
+			w, newFakeNumberRange(0, 20), DigitsPerRow(10), bufferSize(1))
+		assert.Equal(t, i, n)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,81 @@
+package numprint
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFimage(t *testing.T) {
+	options := []Option{DigitsPerRow(10), MissingDigit('?')}
+	p := Between(3, 15)
+	img, written := decodeImage(t, newFakeNumber(), p, options...)
+	lines := []string{"  0.???45 67890", "10  12345"}
+	assert.Equal(
+		t, lines, strings.Split(Sprint(newFakeNumber(), p, options...), "\n"))
+	assert.Equal(
+		t,
+		image.Rect(
+			0, 0, 2*imageMargin+15*cellWidth, 2*imageMargin+2*cellHeight),
+		img.Bounds())
+	assert.Positive(t, written)
+	assert.Zero(t, glyphAt(img, 0, 0))
+	assert.Equal(t, glyphs['0'], glyphAt(img, 2, 0))
+	assert.Equal(t, glyphs['.'], glyphAt(img, 3, 0))
+	assert.Equal(t, glyphs['?'], glyphAt(img, 4, 0))
+	assert.Equal(t, glyphs['4'], glyphAt(img, 7, 0))
+	assert.Equal(t, glyphs['0'], glyphAt(img, 14, 0))
+	assert.Equal(t, glyphs['1'], glyphAt(img, 0, 1))
+	assert.Zero(t, glyphAt(img, 2, 1))
+	assert.Equal(t, glyphs['5'], glyphAt(img, 8, 1))
+}
+
+func TestFimageUnknownAndLowerCase(t *testing.T) {
+	img, _ := decodeImage(
+		t, newFakeNumber(), UpTo(3), ShowCount(false),
+		CustomDigits(
+			[10]rune{'a', 'b', '~', 'd', 'e', 'f', 'g', 'h', 'i', 'j'}))
+	assert.Equal(t, glyphs['B'], glyphAt(img, 2, 0))
+	assert.Equal(t, unknownGlyph, glyphAt(img, 3, 0))
+	assert.Equal(t, glyphs['D'], glyphAt(img, 4, 0))
+}
+
+func TestFimageError(t *testing.T) {
+	_, err := Fimage(&bytes.Buffer{}, newFakeNumber(), UpTo(5), Radix(1))
+	assert.Error(t, err)
+	_, err = Fimage(errorWriter{}, newFakeNumber(), UpTo(5))
+	assert.Error(t, err)
+}
+
+func decodeImage(
+	t *testing.T, s Printable, p Positions, options ...Option) (
+	image.Image, int) {
+	t.Helper()
+	var buffer bytes.Buffer
+	written, err := Fimage(&buffer, s, p, options...)
+	assert.NoError(t, err)
+	assert.Equal(t, buffer.Len(), written)
+	img, err := png.Decode(&buffer)
+	assert.NoError(t, err)
+	return img, written
+}
+
+// glyphAt returns the glyph drawn in img at the given cell.
+func glyphAt(img image.Image, cell, row int) [glyphHeight]uint8 {
+	var result [glyphHeight]uint8
+	x := imageMargin + cell*cellWidth
+	y := imageMargin + row*cellHeight + glyphTop
+	for i := range result {
+		for col := 0; col < glyphWidth; col++ {
+			r, _, _, _ := img.At(x+col, y+i).RGBA()
+			if r == 0 {
+				result[i] |= 1 << (glyphWidth - 1 - col)
+			}
+		}
+	}
+	return result
+}
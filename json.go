@@ -0,0 +1,92 @@
+package numprint
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+)
+
+// Fjson writes the digits of s at positions p to w as JSON. For each range
+// in p, Fjson writes an object like {"start":0,"end":3,"digits":[3,1,4]}
+// where digits has one element for each position in the range and null
+// for positions where s has no digit. If p has exactly one range, Fjson
+// writes just that object; otherwise Fjson writes an array of objects.
+// Fjson follows the JSON with a line feed as json.Encoder does. Fjson
+// streams the digits to w without holding them in memory. Fjson returns the
+// number of bytes written and any error encountered.
+func Fjson(w io.Writer, s Printable, p Positions) (written int, err error) {
+	cWriter := &countingWriter{delegate: w}
+	writer := bufio.NewWriter(cWriter)
+	err = writeJSON(writer, s, p)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	return cWriter.bytesWritten, err
+}
+
+func writeJSON(w *bufio.Writer, s Printable, p Positions) error {
+	isArray := len(p.ranges) != 1
+	if isArray {
+		if err := w.WriteByte('['); err != nil {
+			return err
+		}
+	}
+	for i, pr := range p.ranges {
+		if i > 0 {
+			if err := w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		if err := writeJSONRange(w, s, pr); err != nil {
+			return err
+		}
+	}
+	if isArray {
+		if err := w.WriteByte(']'); err != nil {
+			return err
+		}
+	}
+	return w.WriteByte('\n')
+}
+
+func writeJSONRange(w *bufio.Writer, s Printable, pr PositionRange) error {
+	var scratch []byte
+	scratch = append(scratch, `{"start":`...)
+	scratch = strconv.AppendInt(scratch, int64(pr.Start), 10)
+	scratch = append(scratch, `,"end":`...)
+	scratch = strconv.AppendInt(scratch, int64(pr.End), 10)
+	scratch = append(scratch, `,"digits":[`...)
+	if _, err := w.Write(scratch); err != nil {
+		return err
+	}
+	next := pr.Start
+	writeDigit := func(digit []byte) error {
+		if next > pr.Start {
+			if err := w.WriteByte(','); err != nil {
+				return err
+			}
+		}
+		next++
+		_, err := w.Write(digit)
+		return err
+	}
+	null := []byte("null")
+	for posit, digit := range s.AllInRange(pr.Start, pr.End) {
+		for next < posit {
+			if err := writeDigit(null); err != nil {
+				return err
+			}
+		}
+		scratch = strconv.AppendInt(scratch[:0], int64(digit), 10)
+		if err := writeDigit(scratch); err != nil {
+			return err
+		}
+	}
+	for next < pr.End {
+		if err := writeDigit(null); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString("]}")
+	return err
+}
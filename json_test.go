@@ -0,0 +1,54 @@
+package numprint
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJson(t *testing.T) {
+	var builder strings.Builder
+	n, err := Fjson(&builder, newFakeNumberRange(2, 100), UpTo(5))
+	assert.NoError(t, err)
+	expected := `{"start":0,"end":5,"digits":[null,null,3,4,5]}` + "\n"
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, len(expected), n)
+	assert.True(t, json.Valid([]byte(builder.String())))
+}
+
+func TestJsonMultipleRanges(t *testing.T) {
+	var builder strings.Builder
+	var pb PositionsBuilder
+	_, err := Fjson(
+		&builder,
+		newFakeNumberRange(0, 12),
+		pb.AddRange(0, 2).AddRange(10, 14).Build())
+	assert.NoError(t, err)
+	expected := `[{"start":0,"end":2,"digits":[1,2]},` +
+		`{"start":10,"end":14,"digits":[1,2,null,null]}]` + "\n"
+	assert.Equal(t, expected, builder.String())
+	var decoded []struct {
+		Start  int
+		End    int
+		Digits []*int
+	}
+	assert.NoError(t, json.Unmarshal([]byte(builder.String()), &decoded))
+	assert.Len(t, decoded, 2)
+	assert.Nil(t, decoded[1].Digits[3])
+}
+
+func TestJsonEmpty(t *testing.T) {
+	var builder strings.Builder
+	_, err := Fjson(&builder, newFakeNumber(), Positions{})
+	assert.NoError(t, err)
+	assert.Equal(t, "[]\n", builder.String())
+}
+
+func TestJsonError(t *testing.T) {
+	w := &maxBytesWriter{maxBytes: 5000}
+	n, err := Fjson(w, newFakeNumber(), UpTo(10000))
+	assert.Error(t, err)
+	assert.Equal(t, 5000, n)
+}
@@ -0,0 +1,112 @@
+package numprint
+
+import (
+	"context"
+	"io"
+	"strings"
+)
+
+var latexReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`,
+	"{", `\{`,
+	"}", `\}`,
+	"$", `\$`,
+	"&", `\&`,
+	"#", `\#`,
+	"^", `\textasciicircum{}`,
+	"_", `\_`,
+	"%", `\%`,
+	"~", `\textasciitilde{}`,
+)
+
+// Flatex writes all the digits of s to w as a LaTeX array environment for
+// use in math mode. Each row of digits is a row of the array, and each
+// column of digits is a column of the array set in \texttt. When showing
+// the digit count, the first column of the array holds the count. Flatex
+// escapes characters special to LaTeX such as those that CustomDigits or
+// MissingDigit might introduce. Flatex uses the same defaults as Fwrite,
+// but it ignores options that affect only plain text such as
+// ColumnSeparator. Flatex returns the number of bytes written and any error
+// encountered.
+func Flatex(w io.Writer, s Writable, options ...Option) (
+	written int, err error) {
+	settings := writeSettings(options)
+	format := &latexFormat{columns: settings.latexColumns(endOf(s))}
+	printer := newGridPrinter(w, format, latexReplacer.Replace, settings)
+	fromIterator(context.Background(), s.All(), printer)
+	printer.Finish()
+	return printer.BytesWritten(), printer.Err()
+}
+
+// latexColumns returns the column specification for the LaTeX array that
+// holds maxDigits digits.
+func (p *printerSettings) latexColumns(maxDigits int) string {
+	digits := p.digitsPerRow
+	if digits <= 0 {
+		digits = maxDigits
+	}
+	layout := p.columnLayout()
+	layout.alignRight = false
+	columns := 1
+	for slot := 1; slot < digits; slot++ {
+		if _, ok := layout.columnAt(slot, digits); ok {
+			columns++
+		}
+	}
+	result := strings.Repeat("l", columns)
+	if p.showCount && p.digitsPerRow > 0 {
+		result = "r" + result
+	}
+	return result
+}
+
+type latexFormat struct {
+	columns string
+	rows    int
+}
+
+func (l *latexFormat) Start(w bufferedWriter) error {
+	_, err := w.WriteString(`\begin{array}{` + l.columns + "}\n")
+	return err
+}
+
+func (l *latexFormat) StartRow(w bufferedWriter, count string) error {
+	if l.rows > 0 {
+		if _, err := w.WriteString(" \\\\\n"); err != nil {
+			return err
+		}
+	}
+	l.rows++
+	if count != "" {
+		if _, err := w.WriteString(count + " & "); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString(`\texttt{`)
+	return err
+}
+
+func (l *latexFormat) NextColumn(w bufferedWriter) error {
+	_, err := w.WriteString(`} & \texttt{`)
+	return err
+}
+
+func (l *latexFormat) Digit(w bufferedWriter, digit string) error {
+	_, err := w.WriteString(digit)
+	return err
+}
+
+func (l *latexFormat) EndRow(w bufferedWriter) error {
+	_, err := w.WriteString("}")
+	return err
+}
+
+func (l *latexFormat) Finish(w bufferedWriter) error {
+	if l.rows > 0 {
+		if _, err := w.WriteString("\n"); err != nil {
+			return err
+		}
+	}
+	_, err := w.WriteString("\\end{array}\n")
+	return err
+}
@@ -0,0 +1,78 @@
+package numprint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLatex(t *testing.T) {
+	var builder strings.Builder
+	n, err := Flatex(
+		&builder,
+		newFakeNumberRange(3, 23),
+		DigitsPerRow(10),
+		DigitsPerColumn(4),
+		MissingDigit('_'))
+	assert.NoError(t, err)
+	expected := `\begin{array}{rlll}
+0 & \texttt{\_\_\_4} & \texttt{5678} & \texttt{90} \\
+10 & \texttt{1234} & \texttt{5678} & \texttt{90} \\
+20 & \texttt{123}
+\end{array}
+`
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, len(expected), n)
+}
+
+func TestLatexNoCount(t *testing.T) {
+	var builder strings.Builder
+	_, err := Flatex(
+		&builder,
+		newFakeNumberRange(0, 7),
+		ShowCount(false),
+		DigitsPerRow(0),
+		DigitsPerColumn(5))
+	assert.NoError(t, err)
+	expected := `\begin{array}{ll}
+\texttt{12345} & \texttt{67}
+\end{array}
+`
+	assert.Equal(t, expected, builder.String())
+}
+
+func TestLatexColumnWidths(t *testing.T) {
+	var builder strings.Builder
+	_, err := Flatex(
+		&builder,
+		newFakeNumberRange(0, 7),
+		ShowCount(false),
+		DigitsPerRow(0),
+		ColumnWidths([]int{1, 3}))
+	assert.NoError(t, err)
+	expected := `\begin{array}{lll}
+\texttt{1} & \texttt{234} & \texttt{567}
+\end{array}
+`
+	assert.Equal(t, expected, builder.String())
+}
+
+func TestLatexEmpty(t *testing.T) {
+	var builder strings.Builder
+	_, err := Flatex(&builder, newFakeNumberRange(0, 0))
+	assert.NoError(t, err)
+	expected := "\\begin{array}{r" + strings.Repeat("l", 10) + "}\n" +
+		"\\end{array}\n"
+	assert.Equal(t, expected, builder.String())
+}
+
+func TestLatexError(t *testing.T) {
+	for i := 0; i < 100; i += 7 {
+		w := &maxBytesWriter{maxBytes: i}
+		n, err := Flatex(
+			w, newFakeNumberRange(0, 20), DigitsPerRow(10), bufferSize(1))
+		assert.Equal(t, i, n)
+		assert.Error(t, err)
+	}
+}
@@ -0,0 +1,101 @@
+package numprint
+
+import (
+	"unicode/utf8"
+)
+
+// LayoutInfo describes how printing lays out digits.
+type LayoutInfo struct {
+
+	// The number of rows of digits not counting the ruler, column headers,
+	// or blank lines.
+	Rows int
+
+	// The number of digits in each full row.
+	DigitsPerRow int
+
+	// The number of columns in each full row.
+	ColumnsPerRow int
+
+	// The width in runes of the left margin including any RowPrefix.
+	MarginWidth int
+
+	// About how many bytes printing writes. Same as EstimateSize.
+	Bytes int
+}
+
+// Layout returns how Fprint would lay out the digits at positions 0 up to
+// end with options without printing anything. Layout works only with the
+// options, so like EstimateSize, it does not know which digits are missing
+// and may count more rows than Fprint prints for sparse Positions.
+func Layout(end int, options ...Option) LayoutInfo {
+	settings := printSettings(options)
+	result := LayoutInfo{Bytes: settings.estimateSize(end)}
+	if end <= 0 {
+		return result
+	}
+	settings = settings.withLayout(end)
+	rows, digits, lastRow := settings.rows(end)
+	result.Rows = rows
+	result.DigitsPerRow = settings.visibleDigitsPerRow()
+	if result.DigitsPerRow <= 0 {
+		result.DigitsPerRow = lastRow
+	}
+	if digits > 0 {
+		result.ColumnsPerRow = 1
+	}
+	columns := settings.columnLayout()
+	for slot := 1; slot < result.DigitsPerRow; slot++ {
+		if _, ok := columns.columnAt(slot, result.DigitsPerRow); ok {
+			result.ColumnsPerRow++
+		}
+	}
+	result.MarginWidth = settings.computeRowStarter(end).Width()
+	return result
+}
+
+// RowWidth returns the width in runes of the widest full row of digits
+// that Fprint prints for positions 0 up to end with options. The width
+// includes the left margin, the digits, the separators between columns,
+// and any Indent, RowPrefix, RowSuffix, or decimal separator that
+// LeadingInteger adds. RowWidth needs end because the width of the left
+// margin depends on the largest digit count. Unlike EstimateSize and
+// MaxLineBytes which work in bytes, RowWidth works in runes for lining
+// things up in a terminal. RowWidth does not count ANSI escape codes for
+// highlighting. With Verbose, the width includes the "pos:" labels of the
+// last full row. If end <= 0, RowWidth returns 0.
+func RowWidth(end int, options ...Option) int {
+	if end <= 0 {
+		return 0
+	}
+	settings := printSettings(options).withLayout(end)
+	digits := settings.visibleDigitsPerRow()
+	if digits <= 0 {
+		_, _, digits = settings.rows(end)
+	}
+	columns := settings.columnLayout()
+	separators, _ := columns.separatorsSize(1, digits, digits)
+	result := max(settings.indent, 0) +
+		settings.computeRowStarter(end).Width() +
+		digits*settings.digitWidth() + separators +
+		utf8.RuneCountInString(settings.rowSuffix)
+	if settings.leadingInteger > 0 && settings.leadingInteger < end {
+		result++
+	}
+	if settings.verbose {
+		result += settings.widestLabels(end, digits)
+	}
+	return result
+}
+
+// widestLabels returns the width of the "pos:" labels that Verbose adds to
+// the last full row of digits when printing the digits at positions 0 up
+// to end. digits is the number of digits in a full row. These settings
+// must already have their layout.
+func (p *printerSettings) widestLabels(end, digits int) int {
+	start := max(p.columnOffset, 0)
+	if p.digitsPerRow > 0 && end >= p.digitsPerRow {
+		start += (end/p.digitsPerRow - 1) * p.digitsPerRow
+	}
+	return labelsSize(start, start+digits)
+}
@@ -0,0 +1,81 @@
+package numprint
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLayout(t *testing.T) {
+	options := []Option{DigitsPerRow(12), DigitsPerColumn(5)}
+	info := Layout(100, options...)
+	actual := Sprint(newFakeNumber(), UpTo(100), options...)
+	lines := strings.Split(actual, "\n")
+	assert.Equal(t, LayoutInfo{
+		Rows:          9,
+		DigitsPerRow:  12,
+		ColumnsPerRow: 3,
+		MarginWidth:   4,
+		Bytes:         len(actual),
+	}, info)
+	assert.Len(t, lines, info.Rows)
+	assert.Equal(t, "12  34567 89012 34", lines[1])
+}
+
+func TestLayoutVerbose(t *testing.T) {
+	options := []Option{DigitsPerRow(10), Verbose(true)}
+	info := Layout(100, options...)
+	actual := Sprint(newFakeNumber(), UpTo(100), options...)
+	assert.Equal(t, len(actual), info.Bytes)
+	assert.Equal(t, 10, info.ColumnsPerRow)
+	assert.Len(t, strings.Split(actual, "\n"), info.Rows)
+}
+
+func TestLayoutNoRows(t *testing.T) {
+	info := Layout(
+		23, DigitsPerRow(0), ShowCount(false), RowPrefix("> "),
+		LeadingDecimal(false))
+	assert.Equal(t, LayoutInfo{
+		Rows:          1,
+		DigitsPerRow:  23,
+		ColumnsPerRow: 5,
+		MarginWidth:   2,
+		Bytes:         29,
+	}, info)
+	assert.Equal(t, LayoutInfo{}, Layout(0))
+}
+
+func TestRowWidth(t *testing.T) {
+	optionSets := [][]Option{
+		{DigitsPerRow(12), DigitsPerColumn(5)},
+		{DigitsPerRow(10), MissingDigits("··"), ColumnSeparator(" │ ")},
+		{DigitsPerRow(10), RowPrefix("» "), RowSuffix(" «"), Indent(2)},
+		{DigitsPerRow(10), LeadingInteger(3), Sign(true)},
+		{DigitsPerRow(16), WordsPerGroup(2), DigitsPerColumn(4)},
+		{DigitsPerRow(10), Verbose(true)},
+		{DigitsPerRow(8), ColumnOffset(2), ColumnWindow(3), Verbose(true)},
+	}
+	for _, options := range optionSets {
+		widest := 0
+		for line := range strings.Lines(
+			Sprint(newFakeNumber(), UpTo(100), options...)) {
+			widest = max(
+				widest, utf8.RuneCountInString(strings.TrimSuffix(line, "\n")))
+		}
+		assert.Equal(t, widest, RowWidth(100, options...))
+	}
+	assert.Equal(t, len("0.12345 67"), RowWidth(7, DigitsPerRow(0)))
+	assert.Zero(t, RowWidth(0))
+	options := []Option{DigitsPerRow(0), Verbose(true)}
+	assert.Equal(
+		t,
+		len(Sprint(newFakeNumber(), UpTo(12), options...)),
+		RowWidth(12, options...))
+	options = []Option{DigitsPerRow(10), LeadingInteger(20)}
+	assert.Equal(
+		t,
+		len("10  12345 67890"),
+		RowWidth(20, options...))
+}
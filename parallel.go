@@ -0,0 +1,221 @@
+package numprint
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+)
+
+// FprintParallel works like Fprint except that it formats the digits
+// using up to workers goroutines at once. FprintParallel splits p into
+// pieces made of whole rows, formats each piece into its own buffer, and
+// then writes the buffers to w in order, so the output is the same as
+// Fprint. FprintParallel falls back to Fprint if workers is less than 2,
+// if there are no separate rows, or if options include OnRow,
+// MarkdownFence, HighlightLast, TruncationMarker, GapFill(GapSkip),
+// CollapseGaps, Strict, RowsPerPage, OnProgress, Limit, MaxRows, OnDigit,
+// Checksum, SkipFirstCount, or AppendChecksumLine as these depend on
+// printing in one pass. FprintParallel also falls back to Fprint for
+// DecimalColumn with Indent. s must be safe to call from multiple
+// goroutines at once.
+// FprintParallel returns the number of bytes written and any error
+// encountered.
+func FprintParallel(
+	w io.Writer,
+	s Printable,
+	p Positions,
+	workers int,
+	options ...Option) (written int, err error) {
+	settings := printSettings(options)
+	settings.reverse = false
+	end := p.End()
+	layout := settings.withLayout(end)
+	if workers < 2 || end == 0 || !layout.parallelizable() {
+		return Fprint(w, s, p, options...)
+	}
+	rows := layout.chunkRows(p, workers)
+	chunks := make([]parallelChunk, len(rows)-1)
+	var wg sync.WaitGroup
+	for i := range chunks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			chunks[i].print(s, p, rows[i], rows[i+1], settings)
+		}()
+	}
+	wg.Wait()
+	finishChunks(chunks)
+	cWriter := &countingWriter{delegate: w}
+	writer := settings.newBufferedWriter(cWriter)
+	var ruler string
+	if header := layout.header(end); header != "" {
+		ruler = header + layout.newline()
+	}
+	err = writeChunks(writer, chunks, ruler, settings)
+	if flushErr := writer.Flush(); err == nil {
+		err = flushErr
+	}
+	return cWriter.bytesWritten, err
+}
+
+// parallelizable returns true if printing with these settings can be split
+// into pieces of whole rows.
+func (p *printerSettings) parallelizable() bool {
+	return p.digitsPerRow > 0 &&
+		p.onRow == nil &&
+		!p.markdownFence &&
+		p.highlightLast == "" &&
+		p.truncationMarker == "" &&
+		!p.skipsGaps() &&
+		!p.strict &&
+		p.rowsPerPage <= 0 &&
+		p.onProgress == nil &&
+		p.limit <= 0 &&
+		p.checksum == nil &&
+		p.maxRows <= 0 &&
+		!p.appendChecksumLine &&
+		p.onDigit == nil &&
+		!p.skipFirstCount &&
+		!p.separatorStartsRow() &&
+		(p.decimalColumn <= 0 || p.indent <= 0)
+}
+
+// separatorStartsRow returns true if the decimal separator that
+// LeadingInteger adds goes right before the first digit of a row other
+// than the first. Printing writes that separator at the end of the row
+// before, so it cannot go in a separate piece.
+func (p *printerSettings) separatorStartsRow() bool {
+	offset := max(p.columnOffset, 0)
+	return p.leadingInteger > offset &&
+		(p.leadingInteger-offset)%p.digitsPerRow == 0
+}
+
+// chunkRows returns the rows at which to split p into at most k pieces of
+// about equal numbers of positions. The first row is always 0, and the
+// last row is the row past the end of p.
+func (p *printerSettings) chunkRows(positions Positions, k int) []int {
+	result := []int{0}
+	for _, piece := range positions.Split(k)[1:] {
+		row := piece.ranges[0].Start / p.digitsPerRow
+		if row > result[len(result)-1] {
+			result = append(result, row)
+		}
+	}
+	lastRow := (positions.End() + p.digitsPerRow - 1) / p.digitsPerRow
+	if lastRow > result[len(result)-1] {
+		result = append(result, lastRow)
+	}
+	return result
+}
+
+// parallelChunk is a piece of FprintParallel output.
+type parallelChunk struct {
+	buffer  bytes.Buffer
+	printer *printer
+	endRow  int
+	err     error
+}
+
+// print prints the digits of s in p that are within the rows from
+// startRow up to but not including endRow. print leaves the printer open
+// so that finish can write the missing digits at the end of the piece.
+func (c *parallelChunk) print(
+	s Printable,
+	p Positions,
+	startRow, endRow int,
+	settings *printerSettings) {
+	chunkSettings := *settings
+	chunkSettings.trailingLineFeed = false
+	chunkSettings.ruler = false
+	chunkSettings.columnHeaders = false
+
+	// Indenting the whole output indents the chunks.
+	chunkSettings.indent = 0
+	printer := newPrinter(&c.buffer, p.End(), &chunkSettings)
+	printer.skipRows(startRow)
+	start, stop := startRow*printer.rowLength, endRow*printer.rowLength
+	fromSequenceWithPositions(
+		context.Background(), s, p.Intersect(Between(start, stop)), printer)
+	c.printer = printer
+	c.endRow = endRow
+}
+
+// finish finishes printing this piece. If fill is true, finish first
+// writes the missing digits up to the end of the piece as printing a
+// digit in a later piece would.
+func (c *parallelChunk) finish(fill bool) {
+	if fill {
+		c.printer.fillTo(c.endRow * c.printer.digitsPerRow)
+	}
+	c.printer.Finish()
+	c.err = c.printer.Err()
+}
+
+// finishChunks finishes printing each piece. A piece gets the missing
+// digits at its end only if a later piece printed a digit because
+// printing in one pass writes missing digits only on the way to the next
+// digit.
+func finishChunks(chunks []parallelChunk) {
+	laterDigits := false
+	for i := len(chunks) - 1; i >= 0; i-- {
+		c := &chunks[i]
+		printed := c.printer.started
+		c.finish(laterDigits)
+		laterDigits = laterDigits || printed
+	}
+}
+
+// fillTo writes the missing digits that printing the next digit at or
+// after index would write before reaching index.
+func (p *printer) fillTo(index int) {
+	if p.rowStarter.CountOn() {
+		if !p.started {
+			return
+		}
+		p.skipRowsFor(index)
+	}
+	for p.CanConsume() && p.index < index {
+		p.writeMissing()
+	}
+}
+
+// writeChunks writes chunks to w in order. writeChunks writes ruler
+// before the first chunk that is not empty.
+func writeChunks(
+	w bufferedWriter,
+	chunks []parallelChunk,
+	ruler string,
+	settings *printerSettings) error {
+	started := false
+	for i := range chunks {
+		if chunks[i].buffer.Len() > 0 {
+			if !started {
+				if _, err := w.WriteString(ruler); err != nil {
+					return err
+				}
+			}
+			if started {
+				_, err := w.WriteString(settings.newline())
+				if err != nil {
+					return err
+				}
+				if _, err := w.WriteString(settings.rowSeparator); err != nil {
+					return err
+				}
+			}
+			if _, err := w.Write(chunks[i].buffer.Bytes()); err != nil {
+				return err
+			}
+			started = true
+		}
+		if chunks[i].err != nil {
+			return chunks[i].err
+		}
+	}
+	if settings.trailingLineFeed {
+		_, err := w.WriteString(settings.newline())
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package numprint
+
+import (
+	"crypto/sha256"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFprintParallel(t *testing.T) {
+	positions := []Positions{
+		UpTo(1000),
+		Between(23, 977),
+		buildPositions(0, 3, 100, 150, 151, 152, 900, 901),
+		buildPositions(250, 260),
+		Between(4, 8),
+		Between(58, 70),
+		{},
+	}
+	optionSets := [][]Option{
+		nil,
+		{DigitsPerRow(7), DigitsPerColumn(3), RowSeparator("-\n")},
+		{ShowCount(false)},
+		{ShowCount(false), LeadingDecimal(false), TrailingLF(true)},
+		{DigitsPerRow(10), ColumnOffset(3), ColumnWindow(4), Ruler(true)},
+		{DigitsPerRow(10), LeadingInteger(3), CountGrouping(true)},
+		{GapFill(GapZero), Highlight(map[int]string{3: "1", 150: "1;31"})},
+		{DigitsPerRow(7), DigitsPerColumn(3), Align(AlignRight), Ruler(true)},
+		{DigitsPerRow(6), LeadingInteger(6)},
+		{DigitsPerRow(10), ColumnOffset(2), LeadingInteger(12)},
+		{DigitsPerRow(50), ColumnWindow(3)},
+		{DigitsPerRow(50), ColumnWindow(3), ShowCount(false)},
+		{DigitsPerRow(10), ShowCount(false)},
+		{DigitsPerRow(10), LeadingInteger(2), DecimalColumn(12), Indent(2)},
+		{DigitsPerRow(10), Verbose(true)},
+	}
+	for _, p := range positions {
+		for _, options := range optionSets {
+			for _, s := range []Printable{
+				fakeNumber{}, newFakeNumberRange(0, 125)} {
+				expected := Sprint(s, p, options...)
+				for _, workers := range []int{1, 2, 3, 8, 100} {
+					var sb strings.Builder
+					n, err := FprintParallel(&sb, s, p, workers, options...)
+					assert.NoError(t, err)
+					assert.Equal(t, expected, sb.String(), options)
+					assert.Equal(t, sb.Len(), n)
+				}
+			}
+		}
+	}
+}
+
+func TestFprintParallelChecksum(t *testing.T) {
+	digits := make(fakeDigits, 1000)
+	for i := range digits {
+		digits[i] = (i*i*7 + i/3) % 10
+	}
+	options := []Option{DigitsPerRow(10)}
+	expected := sha256.New()
+	expectedText := Sprint(
+		digits, UpTo(1000), append(options, Checksum(expected))...)
+	actual := sha256.New()
+	var sb strings.Builder
+	_, err := FprintParallel(
+		&sb, digits, UpTo(1000), 8, append(options, Checksum(actual))...)
+	assert.NoError(t, err)
+	assert.Equal(t, expectedText, sb.String())
+	assert.Equal(t, expected.Sum(nil), actual.Sum(nil))
+}
+
+func TestFprintParallelError(t *testing.T) {
+	var sb strings.Builder
+	_, err := FprintParallel(&sb, fakeNumber{}, UpTo(1000), 4, Radix(5))
+	assert.Error(t, err)
+	assert.Equal(t, Sprint(fakeNumber{}, UpTo(1000), Radix(5)), sb.String())
+	for i := 0; i < 1300; i += 101 {
+		w := &maxBytesWriter{maxBytes: i}
+		n, err := FprintParallel(w, fakeNumber{}, UpTo(1000), 4)
+		assert.Equal(t, i, n)
+		assert.Error(t, err)
+	}
+}
+
+func BenchmarkFprint(b *testing.B) {
+	for b.Loop() {
+		Fprint(io.Discard, fakeNumber{}, UpTo(1000000))
+	}
+}
+
+func BenchmarkFprintParallel(b *testing.B) {
+	for b.Loop() {
+		FprintParallel(io.Discard, fakeNumber{}, UpTo(1000000), 8)
+	}
+}
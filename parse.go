@@ -0,0 +1,251 @@
+package numprint
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// NoDigit is what Parse returns for each missing digit.
+	NoDigit = -1
+)
+
+var (
+	ansiEscape = regexp.MustCompile("\x1b\\[[0-9;]*m")
+)
+
+// Parse reads text that Fwrite wrote with options and returns the digits
+// in it. The digit at position i goes at index i of the returned slice.
+// Missing digits, including the ones before the first digit printed, come
+// back as NoDigit. Parse uses the same defaults as Fwrite, so to parse
+// what Fprint printed, pass LeadingDecimal(true) and TrailingLF(false).
+// Parse understands the options that lay out the digits such as
+// DigitsPerRow, DigitsPerColumn, ColumnSeparator, WordsPerGroup,
+// GroupSeparator, RowSeparator, ShowCount, CountGrouping, CountRadix,
+// LeadingDecimal, LeadingInteger, DecimalSeparator, MissingDigit,
+// MissingDigits, CustomDigits, Radix, UpperCase, LineEnding, Ruler,
+// ColumnHeaders, and Align. Parse ignores ANSI escape codes so it also
+// works with Highlight. Parse does not understand options that change
+// what the digit count shows or that change the layout in other ways such
+// as CountFormat, CountOffset, CountThreshold, Reverse, ColumnOffset,
+// ColumnWindow, GapFill(GapSkip), CollapseGaps, RowsPerPage, or
+// MarkdownFence. Rather than allocate a huge slice, Parse returns an error
+// if a digit count is more than the number of digits per row times the
+// length of the input.
+func Parse(r io.Reader, options ...Option) ([]int, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	settings := writeSettings(options)
+	var digits digitSet
+	if err := digits.Init(settings); err != nil {
+		return nil, err
+	}
+	text := ansiEscape.ReplaceAllString(string(content), "")
+	newline := settings.newline()
+	text = strings.TrimSuffix(text, newline)
+	lines := 0
+	if settings.ruler {
+		lines++
+	}
+	if settings.columnHeaders {
+		lines++
+	}
+	if lines > 0 {
+		parts := strings.SplitN(text, newline, lines+1)
+		if len(parts) <= lines {
+			return nil, nil
+		}
+		text = parts[lines]
+	}
+	if text == "" {
+		return nil, nil
+	}
+	parser := digitParser{
+		settings: settings,
+		digits:   &digits,
+		maxPosit: len(content) * max(settings.digitsPerRow, 1),
+	}
+	for i, row := range strings.Split(text, newline+settings.rowSeparator) {
+		if err := parser.parseRow(i, row); err != nil {
+			return nil, fmt.Errorf("numprint: row %d: %w", i, err)
+		}
+	}
+	return parser.result, nil
+}
+
+// digitParser parses rows of digits for Parse.
+type digitParser struct {
+	settings *printerSettings
+	digits   *digitSet
+	result   []int
+	next     int
+	maxPosit int
+}
+
+// parseRow parses row, the row at rowIndex, and adds its digits to the
+// result.
+func (d *digitParser) parseRow(rowIndex int, row string) error {
+	posit, area, err := d.parseMargin(rowIndex, row)
+	if err != nil {
+		return err
+	}
+	settings := d.settings
+	rowLength := settings.digitsPerRow
+	if rowLength <= 0 {
+		rowLength = d.digitsIn(posit, area)
+	}
+	missing := settings.missingText()
+	columns := settings.columnLayout()
+	decimalSeparator := string(settings.decimalSeparator)
+	width := settings.digitWidth()
+	started := false
+	for slot := 0; ; slot++ {
+		if started && settings.leadingInteger > 0 &&
+			posit == settings.leadingInteger &&
+			strings.HasPrefix(area, decimalSeparator) {
+			area = area[len(decimalSeparator):]
+		}
+		if area == "" {
+			return nil
+		}
+		if separator := columns.separatorAt(slot, rowLength); separator != "" {
+
+			// Right aligned rows have blank space in place of separators
+			// to the left of their first digit.
+			blank := strings.Repeat(
+				" ", utf8.RuneCountInString(separator))
+			if columns.alignRight && !started &&
+				strings.HasPrefix(area, blank) {
+				area = area[len(blank):]
+			} else if strings.HasPrefix(area, separator) {
+				area = area[len(separator):]
+			} else {
+				return fmt.Errorf("missing column separator at %q", area)
+			}
+		}
+		cell, rest := splitRunes(area, width)
+		area = rest
+		if columns.alignRight && !started &&
+			strings.TrimLeft(cell, " ") == "" {
+			continue
+		}
+		started = true
+		if cell == missing {
+			d.set(posit, NoDigit)
+			posit++
+			continue
+		}
+		digit, ok := d.digitOf(strings.TrimLeft(cell, " "))
+		if !ok {
+			return fmt.Errorf("invalid digit %q", cell)
+		}
+		d.set(posit, digit)
+		posit++
+	}
+}
+
+// parseMargin strips the left margin from row, the row at rowIndex.
+// parseMargin returns the position of the first digit in the row and
+// what comes after the margin.
+func (d *digitParser) parseMargin(
+	rowIndex int, row string) (posit int, area string, err error) {
+	settings := d.settings
+	leadingDecimal := "0" + string(settings.decimalSeparator)
+	if rowIndex == 0 && settings.showLeadingDecimal() {
+		trimmed := strings.TrimLeft(row, " ")
+		if strings.HasPrefix(trimmed, leadingDecimal) {
+			return 0, trimmed[len(leadingDecimal):], nil
+		}
+	}
+	if !settings.showCount || settings.digitsPerRow <= 0 {
+		posit = d.next
+		if settings.showLeadingDecimal() {
+			row = strings.TrimPrefix(row, "  ")
+		}
+		if settings.showCount && rowIndex == 0 {
+			row = strings.TrimPrefix(row, "0  ")
+		}
+		return posit, row, nil
+	}
+	trimmed := strings.TrimLeft(row, " ")
+	end := strings.Index(trimmed, "  ")
+	if end < 0 {
+		return 0, "", fmt.Errorf("missing digit count in %q", row)
+	}
+	count := trimmed[:end]
+	if settings.countGrouping {
+		sep := settings.countGroupingSeparator
+		if sep == "" {
+			sep = ","
+		}
+		count = strings.ReplaceAll(count, sep, "")
+	}
+	parsed, err := strconv.ParseInt(count, settings.countBase(), 0)
+	posit = int(parsed)
+	if err != nil {
+		return 0, "", fmt.Errorf("invalid digit count %q", trimmed[:end])
+	}
+	if posit < d.next {
+		return 0, "", fmt.Errorf("digit count %q goes backward", trimmed[:end])
+	}
+	if posit > d.maxPosit {
+		return 0, "", fmt.Errorf("digit count %q is too large", trimmed[:end])
+	}
+	return posit, trimmed[end+2:], nil
+}
+
+// digitsIn returns the number of digits in area, the whole of a row that
+// has no set number of digits and that starts at posit.
+func (d *digitParser) digitsIn(posit int, area string) int {
+	settings := d.settings
+	columns := settings.columnLayout()
+	width := utf8.RuneCountInString(area)
+	result := 0
+	for used := 0; used < width; {
+		result++
+		used, _ = columns.separatorsSize(1, result, result)
+		used += result * settings.digitWidth()
+		if settings.leadingInteger > posit &&
+			settings.leadingInteger < posit+result {
+			used++
+		}
+	}
+	return result
+}
+
+func (d *digitParser) digitOf(text string) (int, bool) {
+	r, size := utf8.DecodeRuneInString(text)
+	if size == 0 || size != len(text) {
+		return 0, false
+	}
+	for digit := 0; digit < d.digits.radix; digit++ {
+		if d.digits.Rune(digit) == r {
+			return digit, true
+		}
+	}
+	return 0, false
+}
+
+func (d *digitParser) set(posit, digit int) {
+	for len(d.result) <= posit {
+		d.result = append(d.result, NoDigit)
+	}
+	d.result[posit] = digit
+	d.next = posit + 1
+}
+
+// splitRunes splits s after its first n runes.
+func splitRunes(s string, n int) (string, string) {
+	index := 0
+	for ; n > 0 && index < len(s); n-- {
+		_, size := utf8.DecodeRuneInString(s[index:])
+		index += size
+	}
+	return s[:index], s[index:]
+}
@@ -0,0 +1,82 @@
+package numprint
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRoundTrip(t *testing.T) {
+	optionSets := [][]Option{
+		nil,
+		{DigitsPerRow(7), DigitsPerColumn(3), RowSeparator("-\n")},
+		{ShowCount(false)},
+		{DigitsPerRow(0), DigitsPerColumn(4)},
+		{DigitsPerRow(0), ShowCount(false), LeadingDecimal(true)},
+		{DigitsPerRow(10), LeadingInteger(3), CountGrouping(true)},
+		{DigitsPerRow(10), LeadingInteger(10), DecimalSeparator(',')},
+		{DigitsPerRow(8), MissingDigits("--"), Ruler(true)},
+		{DigitsPerRow(7), DigitsPerColumn(3), Align(AlignRight)},
+		{DigitsPerRow(0), DigitsPerColumn(3), Align(AlignRight)},
+		{DigitsPerRow(16), WordSize(4), WordsPerGroup(2), Align(AlignRight)},
+		{CustomDigits([10]rune{
+			'零', '一', '二', '三', '四', '五', '六', '七', '八', '九'})},
+		{LineEnding("\r\n"), ColumnHeaders(true), LeadingDecimal(true)},
+		{Highlight(map[int]string{2: "1"}), Radix(16)},
+		{DigitsPerRow(8), CountRadix(16), Radix(16), UpperCase(true)},
+	}
+	for _, options := range optionSets {
+		for _, end := range []int{1, 7, 23, 150} {
+			number := newFakeNumberRange(0, end)
+			actual, err := Parse(
+				strings.NewReader(Swrite(number, options...)), options...)
+			assert.NoError(t, err)
+			assert.Equal(t, fakeNumberDigits(end), actual)
+		}
+	}
+}
+
+func TestParseFprint(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(3, 5).AddRange(60, 62).Add(74).Build()
+	options := []Option{DigitsPerRow(10), LeadingDecimal(true)}
+	text := Sprint(fakeNumber{}, p, options...)
+	actual, err := Parse(
+		strings.NewReader(text),
+		append(options, TrailingLF(false))...)
+	assert.NoError(t, err)
+	expected := fakeNumberDigits(75)
+	missing := UpTo(75).Subtract(p)
+	for pr := range missing.All() {
+		for i := pr.Start; i < pr.End; i++ {
+			expected[i] = NoDigit
+		}
+	}
+	assert.Equal(t, expected, actual)
+}
+
+func TestParseErrors(t *testing.T) {
+	_, err := Parse(strings.NewReader("0  12x45\n"))
+	assert.Error(t, err)
+	_, err = Parse(strings.NewReader("0  12345\n"), Radix(40))
+	assert.Error(t, err)
+	_, err = Parse(strings.NewReader(" 0  12345\n-5  12345\n"))
+	assert.Error(t, err)
+	_, err = Parse(strings.NewReader(" 0  12345\n 5  12345\n 3  12\n"))
+	assert.Error(t, err)
+	_, err = Parse(strings.NewReader("999999999999  1\n"))
+	assert.ErrorContains(t, err, "too large")
+	actual, err := Parse(strings.NewReader(""))
+	assert.NoError(t, err)
+	assert.Empty(t, actual)
+}
+
+// fakeNumberDigits returns the first end digits of fakeNumber.
+func fakeNumberDigits(end int) []int {
+	result := make([]int, end)
+	for i := range result {
+		result[i] = (i + 1) % 10
+	}
+	return result
+}
@@ -7,7 +7,10 @@ import (
 
 // PositionsBuilder builds Positions objects. The zero value has no
 // positions in it and is ready to use. Do not copy a PositionsBuilder
-// instance.
+// instance. Ranges may be added in any order and may overlap or touch.
+// The Positions that Build returns always have their ranges sorted by
+// Start with overlapping and adjacent ranges merged, so no position
+// appears twice.
 type PositionsBuilder struct {
 	ranges   []PositionRange
 	unsorted bool
@@ -86,6 +89,24 @@ func Between(start, end int) Positions {
 	return pb.AddRange(start, end).Build()
 }
 
+// FromPositions returns the positions in posits. FromPositions groups
+// consecutive positions into ranges. posits need not be sorted and may
+// have duplicates. FromPositions ignores negative positions.
+func FromPositions(posits []int) Positions {
+	var pb PositionsBuilder
+	for _, posit := range posits {
+		pb.Add(posit)
+	}
+	return pb.Build()
+}
+
+// Range returns the positions from start up to but not including end as a
+// single contiguous range. If end <= start, Range returns no positions.
+// Range is the same as Between.
+func Range(start, end int) Positions {
+	return Between(start, end)
+}
+
 // All returns all the non overlapping ranges of positions in p.
 func (p Positions) All() iter.Seq[PositionRange] {
 	return func(yield func(pr PositionRange) bool) {
@@ -97,6 +118,129 @@ func (p Positions) All() iter.Seq[PositionRange] {
 	}
 }
 
+// Backward returns all the non overlapping ranges of positions in p in
+// descending order. Use PositionRange.Backward to go through the
+// positions within each range from high to low.
+func (p Positions) Backward() iter.Seq[PositionRange] {
+	return func(yield func(pr PositionRange) bool) {
+		for i := len(p.ranges) - 1; i >= 0; i-- {
+			if !yield(p.ranges[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Shift returns p with delta added to each position. Shift drops any
+// positions that delta makes negative.
+func (p Positions) Shift(delta int) Positions {
+	var pb PositionsBuilder
+	for _, pr := range p.ranges {
+		pb.AddRange(pr.Start+delta, pr.End+delta)
+	}
+	return pb.Build()
+}
+
+// Clamp returns the positions in p that come before end. Clamp is the
+// same as p.Intersect(UpTo(end)).
+func (p Positions) Clamp(end int) Positions {
+	var pb PositionsBuilder
+	for _, pr := range p.ranges {
+		if pr.Start >= end {
+			break
+		}
+		pb.AddRange(pr.Start, min(pr.End, end))
+	}
+	return pb.Build()
+}
+
+// Intersect returns the positions that are in both p and other.
+func (p Positions) Intersect(other Positions) Positions {
+	var pb PositionsBuilder
+	i, j := 0, 0
+	for i < len(p.ranges) && j < len(other.ranges) {
+		a, b := p.ranges[i], other.ranges[j]
+		pb.AddRange(max(a.Start, b.Start), min(a.End, b.End))
+		if a.End < b.End {
+			i++
+		} else {
+			j++
+		}
+	}
+	return pb.Build()
+}
+
+// Subtract returns the positions that are in p but not in other.
+func (p Positions) Subtract(other Positions) Positions {
+	var pb PositionsBuilder
+	j := 0
+	for _, pr := range p.ranges {
+		start := pr.Start
+		for j < len(other.ranges) && other.ranges[j].End <= start {
+			j++
+		}
+		for _, cut := range other.ranges[j:] {
+			if cut.Start >= pr.End {
+				break
+			}
+			pb.AddRange(start, cut.Start)
+			start = max(start, cut.End)
+		}
+		pb.AddRange(start, pr.End)
+	}
+	return pb.Build()
+}
+
+// Split splits p into k contiguous pieces in order, each with about the
+// same number of positions. Together the pieces have all the positions in
+// p. If p has fewer than k positions, Split returns one piece for each
+// position. If p has no positions, Split returns nil. Zero or negative k
+// means 1. When printing each piece separately and joining the results,
+// the caller must handle the digit count in the left margin as each piece
+// gets printed with rows that start at the beginning of that piece.
+func (p Positions) Split(k int) []Positions {
+	total := p.Count()
+	k = min(max(k, 1), total)
+	var result []Positions
+	var pb PositionsBuilder
+	i, start := 0, 0
+	for piece := 0; piece < k; piece++ {
+		size := total*(piece+1)/k - total*piece/k
+		for size > 0 {
+			pr := p.ranges[i]
+			if start < pr.Start {
+				start = pr.Start
+			}
+			end := min(start+size, pr.End)
+			pb.AddRange(start, end)
+			size -= end - start
+			start = end
+			if start == pr.End {
+				i++
+			}
+		}
+		result = append(result, pb.Build())
+	}
+	return result
+}
+
+// Contains returns true if posit is one of the positions in p.
+func (p Positions) Contains(posit int) bool {
+	i := sort.Search(len(p.ranges), func(i int) bool {
+		return p.ranges[i].End > posit
+	})
+	return i < len(p.ranges) && p.ranges[i].Start <= posit
+}
+
+// Count returns the total number of positions in p.
+func (p Positions) Count() int {
+	result := 0
+	for _, pr := range p.ranges {
+		result += pr.End - pr.Start
+	}
+	return result
+}
+
 // End returns the last zero based position in p plus 1. If p is the zero
 // value, End returns 0.
 func (p Positions) End() int {
@@ -117,6 +261,17 @@ type PositionRange struct {
 	End int
 }
 
+// Backward returns the positions in pr from End - 1 down to Start.
+func (pr PositionRange) Backward() iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for posit := pr.End - 1; posit >= pr.Start; posit-- {
+			if !yield(posit) {
+				return
+			}
+		}
+	}
+}
+
 func appendNotBefore(item PositionRange, ranges *[]PositionRange) {
 	length := len(*ranges)
 	lastItem := &(*ranges)[length-1]
@@ -55,6 +55,29 @@ func TestPositionsBuilderSorted(t *testing.T) {
 	assert.Equal(t, 200, p.End())
 }
 
+func TestFromPositions(t *testing.T) {
+	p := FromPositions([]int{2, 3, 5, 7, 11, 12, 13})
+	var ranges []PositionRange
+	for pr := range p.All() {
+		ranges = append(ranges, pr)
+	}
+	expected := []PositionRange{
+		{Start: 2, End: 4},
+		{Start: 5, End: 6},
+		{Start: 7, End: 8},
+		{Start: 11, End: 14},
+	}
+	assert.Equal(t, expected, ranges)
+	assert.Equal(t, 14, p.End())
+	assert.Equal(t, p, FromPositions([]int{13, 5, -1, 2, 12, 3, 7, 5, 11}))
+	assert.Zero(t, FromPositions(nil))
+	assert.Equal(
+		t,
+		"0...34. 6.8",
+		Sprint(newFakeNumber(), FromPositions([]int{2, 3, 5, 7}),
+			ShowCount(false)))
+}
+
 func TestPositionsBuilderNegative(t *testing.T) {
 	var pb PositionsBuilder
 	pb.Add(-1)
@@ -77,3 +100,175 @@ func TestPositionsAllExitEarly(t *testing.T) {
 	}
 	assert.Equal(t, PositionRange{Start: 0, End: 10}, firstRange)
 }
+
+func TestPositionsBackward(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 3).AddRange(100, 102).AddRange(5, 7)
+	p := pb.Build()
+	var posits []int
+	for pr := range p.Backward() {
+		for posit := range pr.Backward() {
+			posits = append(posits, posit)
+		}
+	}
+	assert.Equal(t, []int{101, 100, 6, 5, 2, 1, 0}, posits)
+	var lastRange PositionRange
+	for pr := range p.Backward() {
+		lastRange = pr
+		break
+	}
+	assert.Equal(t, PositionRange{Start: 100, End: 102}, lastRange)
+	for range (Positions{}).Backward() {
+		assert.Fail(t, "expected no ranges")
+	}
+}
+
+func TestPositionsContainsAndCount(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 3).AddRange(10, 12).AddRange(20, 21)
+	p := pb.Build()
+	assert.Equal(t, 6, p.Count())
+	for _, posit := range []int{0, 2, 10, 11, 20} {
+		assert.True(t, p.Contains(posit), posit)
+	}
+	for _, posit := range []int{-1, 3, 9, 12, 19, 21} {
+		assert.False(t, p.Contains(posit), posit)
+	}
+	var empty Positions
+	assert.Equal(t, 0, empty.Count())
+	assert.False(t, empty.Contains(0))
+}
+
+func TestPositionsShift(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(2, 5).AddRange(10, 12)
+	p := pb.Build()
+	assert.Equal(t, pb.AddRange(7, 10).AddRange(15, 17).Build(), p.Shift(5))
+	assert.Equal(t, 17, p.Shift(5).End())
+	assert.Equal(t, p, p.Shift(0))
+	assert.Equal(t, pb.AddRange(0, 2).AddRange(7, 9).Build(), p.Shift(-3))
+	assert.Equal(t, pb.AddRange(0, 1).Build(), p.Shift(-11))
+	assert.Zero(t, p.Shift(-12))
+	assert.Zero(t, p.Shift(-100).End())
+	assert.Zero(t, (Positions{}).Shift(3))
+}
+
+func TestPositionsClamp(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(2, 5).AddRange(10, 20).AddRange(30, 40)
+	p := pb.Build()
+	assert.Equal(t, pb.AddRange(2, 5).AddRange(10, 15).Build(), p.Clamp(15))
+	assert.Equal(t, 15, p.Clamp(15).End())
+	assert.Equal(t, pb.AddRange(2, 5).AddRange(10, 20).Build(), p.Clamp(25))
+	assert.Equal(t, pb.AddRange(2, 3).Build(), p.Clamp(3))
+	assert.Equal(t, p, p.Clamp(40))
+	assert.Equal(t, p, p.Clamp(100))
+	assert.Zero(t, p.Clamp(2))
+	assert.Zero(t, p.Clamp(-5))
+	assert.Equal(t, p.Intersect(UpTo(33)), p.Clamp(33))
+}
+
+func TestRange(t *testing.T) {
+	p := Range(3, 10)
+	assert.Equal(
+		t, []PositionRange{{Start: 3, End: 10}}, slices.Collect(p.All()))
+	assert.Equal(t, 10, p.End())
+	assert.Zero(t, Range(10, 3))
+	assert.Zero(t, Range(5, 5))
+	assert.Equal(t, UpTo(7), Range(-2, 7))
+}
+
+func TestPositionsBuilderMerges(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(20, 30).
+		AddRange(22, 25).
+		AddRange(0, 5).
+		AddRange(5, 8).
+		AddRange(28, 35).
+		AddRange(40, 41).
+		AddRange(3, 3).
+		Build()
+	expected := []PositionRange{
+		{Start: 0, End: 8},
+		{Start: 20, End: 35},
+		{Start: 40, End: 41},
+	}
+	assert.Equal(t, expected, slices.Collect(p.All()))
+	assert.Equal(t, 41, p.End())
+}
+
+func buildPositions(ranges ...int) Positions {
+	var pb PositionsBuilder
+	for i := 0; i < len(ranges); i += 2 {
+		pb.AddRange(ranges[i], ranges[i+1])
+	}
+	return pb.Build()
+}
+
+func TestPositionsIntersect(t *testing.T) {
+	p := buildPositions(0, 10, 20, 30, 40, 50)
+
+	// disjoint
+	assert.Equal(
+		t, Positions{}, p.Intersect(buildPositions(10, 20, 30, 40, 50, 60)))
+
+	// nested
+	assert.Equal(
+		t,
+		buildPositions(2, 5, 21, 29),
+		p.Intersect(buildPositions(2, 5, 21, 29)))
+	assert.Equal(t, p, p.Intersect(UpTo(100)))
+
+	// partially overlapping
+	actual := p.Intersect(buildPositions(5, 25, 45, 100))
+	assert.Equal(t, buildPositions(5, 10, 20, 25, 45, 50), actual)
+	assert.Equal(t, 50, actual.End())
+	assert.Equal(t, Positions{}, p.Intersect(Positions{}))
+}
+
+func TestPositionsSubtract(t *testing.T) {
+	p := buildPositions(0, 10, 20, 30, 40, 50)
+
+	// disjoint
+	assert.Equal(t, p, p.Subtract(buildPositions(10, 20, 30, 40, 50, 60)))
+
+	// nested
+	assert.Equal(
+		t,
+		buildPositions(0, 2, 5, 10, 20, 21, 29, 30, 40, 50),
+		p.Subtract(buildPositions(2, 5, 21, 29)))
+	assert.Equal(t, Positions{}, p.Subtract(UpTo(100)))
+
+	// partially overlapping
+	actual := p.Subtract(buildPositions(5, 25, 45, 100))
+	assert.Equal(t, buildPositions(0, 5, 25, 30, 40, 45), actual)
+	assert.Equal(t, 45, actual.End())
+	assert.Equal(t, p, p.Subtract(Positions{}))
+	assert.Equal(
+		t,
+		buildPositions(0, 1, 3, 4, 9, 10),
+		UpTo(10).Subtract(buildPositions(1, 3, 4, 9)))
+}
+
+func TestPositionsSplit(t *testing.T) {
+	p := buildPositions(0, 10, 20, 25, 30, 35)
+	expected := []Positions{
+		buildPositions(0, 6),
+		buildPositions(6, 10, 20, 23),
+		buildPositions(23, 25, 30, 35),
+	}
+	assert.Equal(t, expected, p.Split(3))
+	assert.Equal(t, []Positions{p}, p.Split(1))
+	assert.Equal(t, []Positions{p}, p.Split(0))
+	assert.Len(t, UpTo(4).Split(10), 4)
+	assert.Nil(t, Positions{}.Split(3))
+	var pb PositionsBuilder
+	for _, piece := range UpTo(1000).Split(7) {
+		count := piece.Count()
+		assert.True(t, count == 142 || count == 143)
+		for pr := range piece.All() {
+			pb.AddRange(pr.Start, pr.End)
+		}
+	}
+	assert.Equal(t, UpTo(1000), pb.Build())
+}
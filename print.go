@@ -6,15 +6,40 @@
 // While this package is meant to be used with the data structures in the
 // github.com/keep94/sqrt package, it will work with anything that
 // implements the Printable or Writable interface.
+//
+// The functions in this package that write to an io.Writer buffer what they
+// write and flush it before returning. If the io.Writer already has a
+// Flush() error method, they assume it is buffered and write to it directly
+// leaving it to the caller to flush it.
 package numprint
 
 import (
+	"context"
+	"errors"
+	"hash"
 	"io"
 	"iter"
 	"os"
 	"strings"
 )
 
+const (
+	contextCheckInterval = 1024
+
+	// maxGrowSize is the most that Sprint reserves up front.
+	maxGrowSize = 1 << 20
+)
+
+var (
+	// ErrMissingDigit means that a printer in strict mode came to a missing
+	// digit. See the Strict option.
+	ErrMissingDigit = errors.New("numprint: missing digit")
+
+	// ErrLineTooLong means that not even one digit fits within the bytes
+	// per line that MaxLineBytes allows.
+	ErrLineTooLong = errors.New("numprint: line too long")
+)
+
 // Printable represents a sequence of digits between 0-9 with contiguous
 // positions that can be printed with Print(), Fprint(), or Sprint().
 type Printable interface {
@@ -51,11 +76,219 @@ func DigitsPerRow(count int) Option {
 	})
 }
 
+// FitWidth sets the number of digits per row to the most that fit in a
+// row that is cols runes wide including the left margin and the column
+// separators. If not even one column fits, FitWidth uses one digit per row.
+// FitWidth overrides DigitsPerRow. Zero or negative means use DigitsPerRow.
+func FitWidth(cols int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.fitWidth = cols
+	})
+}
+
+// MaxLineBytes lowers the number of digits per row as needed so that no
+// row takes more than n bytes including the left margin, the column
+// separators, and any RowPrefix and RowSuffix but not counting the line
+// ending or Highlight. MaxLineBytes matters most with CustomDigits that
+// take more than one byte each. If not even one digit fits in n bytes,
+// printing fails with an error wrapping ErrLineTooLong. Zero or negative
+// means no limit, the default.
+func MaxLineBytes(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.maxLineBytes = n
+	})
+}
+
+// ColumnOffset hides the first n digits of each row so that each row shows
+// only the digits from n onward. The digit count in the left margin shows
+// the position of the first visible digit in each row. Together with
+// ColumnWindow, ColumnOffset scrolls wide rows horizontally. If there are
+// no separate rows, ColumnOffset hides the first n digits. LeadingDecimal
+// has no effect when n is positive. Zero or negative means hide nothing, the
+// default.
+func ColumnOffset(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.columnOffset = n
+	})
+}
+
+// ColumnWindow shows at most n digits of each row starting at the digit
+// that ColumnOffset gives. Column separators are placed as if the hidden
+// digits were not there. Zero or negative means show the rest of each row,
+// the default.
+func ColumnWindow(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.columnWindow = n
+	})
+}
+
 // DigitsPerColumn sets the number of digits per column. Zero or negative
 // means no separate columns.
 func DigitsPerColumn(count int) Option {
 	return optionFunc(func(p *printerSettings) {
 		p.digitsPerColumn = count
+		p.columnWidths = nil
+	})
+}
+
+// ColumnWidths sets the number of digits in each successive column of a
+// row. The last width repeats for the rest of the row. For instance,
+// ColumnWidths([]int{1, 5}) puts 1 digit in the first column and 5 digits
+// in each column after that. With Align(AlignRight), the widths apply
+// starting from the right of each row. ColumnWidths ignores widths that
+// are zero or negative; if no widths remain, there are no separate
+// columns. Whichever of ColumnWidths and DigitsPerColumn comes last wins.
+func ColumnWidths(widths []int) Option {
+	var positive []int
+	for _, width := range widths {
+		if width > 0 {
+			positive = append(positive, width)
+		}
+	}
+	return optionFunc(func(p *printerSettings) {
+		p.columnWidths = positive
+		p.digitsPerColumn = 0
+	})
+}
+
+// ColumnSeparator sets the string that separates columns. The default is a
+// single space.
+func ColumnSeparator(sep string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.columnSeparator = sep
+	})
+}
+
+// WordSize is another name for DigitsPerColumn for when the columns are
+// thought of as words. Whichever of WordSize and DigitsPerColumn comes
+// last wins. ColumnSeparator sets the separator between words.
+func WordSize(n int) Option {
+	return DigitsPerColumn(n)
+}
+
+// BinaryGrouping lays out binary digits as bytes if on is true. Each
+// group is a byte of 8 digits made of two 4 digit columns. The column
+// separator goes between the two halves of each byte, and the group
+// separator goes between bytes. For example, ColumnSeparator("_") and
+// GroupSeparator(" ") give rows like 0110_1001 1100_0011. When a row holds
+// more than 8 digits, BinaryGrouping rounds the number of digits per row
+// down to a whole number of bytes. BinaryGrouping overrides DigitsPerColumn,
+// ColumnWidths, and WordsPerGroup. Use BinaryGrouping with Radix(2).
+// Default is off.
+func BinaryGrouping(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.binaryGrouping = on
+	})
+}
+
+// WordsPerGroup groups the columns, or words, of each row into groups of
+// n columns each. The group separator goes between groups in place of
+// the column separator. For example, WordSize(4) and WordsPerGroup(2)
+// print "1234 5678  9012 3456". Groups start at the left of each row, or
+// at the right with Align(AlignRight). Zero or negative n means no
+// groups. Default is no groups.
+func WordsPerGroup(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.wordsPerGroup = n
+	})
+}
+
+// GroupSeparator sets the string that separates groups of columns when
+// WordsPerGroup is given. The default is two spaces.
+func GroupSeparator(sep string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.groupSeparator = sep
+	})
+}
+
+// RowSeparator sets a string to write between rows. For instance, "\n"
+// double spaces the rows. The row separator never gets the digit count in
+// the left margin. The default is no row separator.
+func RowSeparator(sep string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.rowSeparator = sep
+	})
+}
+
+// Ruler prints a line above the first row if on is true. The line shows
+// the position within the row where each column starts lined up with the
+// first digit of that column. Ruler leaves out a position if there is not
+// enough room for it.
+func Ruler(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.ruler = on
+	})
+}
+
+// ColumnHeaders prints a line above the first row if on is true. The line
+// labels each digit with its offset within its column, 0 through
+// DigitsPerColumn - 1, lined up with the digit. If there are no columns,
+// the labels count up from 0 across the row. Labels past 9 show only
+// their last digit so that each label is as wide as a digit. The line
+// goes below the line that Ruler prints. Default is off.
+func ColumnHeaders(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.columnHeaders = on
+	})
+}
+
+// Verbose prints each digit as its position, a colon, and the digit such
+// as "0:3 1:1 2:4" if on is true. Verbose separates every digit with a
+// single space and still puts DigitsPerRow digits on each row, but it
+// ignores the options that group the digits into columns or line them up
+// such as DigitsPerColumn, WordsPerGroup, Align, FitWidth, Ruler, and
+// ColumnHeaders. Verbose also turns off LeadingDecimal and LeadingInteger.
+// Default is off.
+func Verbose(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.verbose = on
+	})
+}
+
+// PadLastRow fills out the last row if it is not full with missing
+// digits, including the column separators, so that it is as wide as the
+// other rows. PadLastRow has no effect when there is only one row with no
+// set number of digits, and it leaves right aligned rows as is since
+// they are already full width. Default is off.
+func PadLastRow(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.padLastRow = on
+	})
+}
+
+// PadWithSpaces makes PadLastRow fill out the last row with spaces
+// instead of missing digits and column separators if on is true. Default
+// is off.
+func PadWithSpaces(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.padWithSpaces = on
+	})
+}
+
+// RowsPerPage breaks the rows into pages of n rows each. Between pages,
+// the page separator goes in place of the row separator. The page
+// separator never comes after the last page. Zero or negative n means no
+// pages. Default is no pages.
+func RowsPerPage(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.rowsPerPage = n
+	})
+}
+
+// PageSeparator sets what goes between pages when RowsPerPage is given.
+// Default is a form feed, "\f".
+func PageSeparator(sep string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.pageSeparator = sep
+	})
+}
+
+// RulerEachPage repeats the ruler and column headers at the top of each
+// page if on is true. RulerEachPage has an effect only with RowsPerPage
+// and either Ruler(true) or ColumnHeaders(true). Default is off.
+func RulerEachPage(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.rulerEachPage = on
 	})
 }
 
@@ -63,23 +296,196 @@ func DigitsPerColumn(count int) Option {
 func ShowCount(on bool) Option {
 	return optionFunc(func(p *printerSettings) {
 		p.showCount = on
+		p.showCountSet = true
+	})
+}
+
+// SkipFirstCount leaves out the digit count on the first row printed if
+// on is true. The first row keeps the blank space where its count would go
+// so that its digits line up with the other rows. Default is off.
+func SkipFirstCount(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.skipFirstCount = on
+	})
+}
+
+// CountRadix shows the digit count in the left margin in base instead of
+// base 10. Counts use 'a' through 'z' for the values 10 through 35. base
+// must be between 2 and 36. Other values mean base 10, the default.
+// CountRadix has no effect with CountFormat.
+func CountRadix(base int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countRadix = base
+	})
+}
+
+// CountOffset adds base to each digit count in the left margin without
+// changing which digits print. Use CountOffset to label the rows of a
+// piece of a longer sequence with positions in that longer sequence. The
+// left margin is wide enough for the counts with base added. CountFormat
+// gets the counts with base added. Default is 0.
+func CountOffset(base int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countOffset = base
+	})
+}
+
+// CountThreshold shows the digit count in the left margin only when
+// printing at least n digits. For Fprint, the number of digits is p.End();
+// for Fwrite, it is the number of digits in s. Whether to show the count is
+// decided before printing starts, along with the width of the margin that
+// RightAlign uses. CountThreshold has no effect unless ShowCount is on.
+// Zero or negative means always show the count, the default.
+func CountThreshold(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countThreshold = n
+	})
+}
+
+// RightAlign right aligns the digit count in the left margin if on is true
+// and left aligns it if on is false. The width of the margin is always
+// that of the largest digit count so that all rows line up. The default is
+// to right align.
+func RightAlign(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.leftAlignCount = !on
+	})
+}
+
+// CountWidth sets the width of the digit count in the left margin to count
+// characters. A digit count needing more than count characters widens the
+// margin for its row only rather than being truncated. Zero or negative
+// means compute the width from the largest digit count. CountWidth has no
+// effect unless the digit count is shown.
+func CountWidth(count int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countWidth = count
+	})
+}
+
+// CountFormat sets how the digit count in the left margin is displayed.
+// The printer calls fn with the zero based position of the first digit of
+// each row and displays what fn returns. fn should return strings of
+// similar length as the printer pads them to the same width so that rows
+// line up. The printer may call fn additional times to compute that
+// width. The default displays the count in base 10.
+func CountFormat(fn func(count int) string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countFormat = fn
+	})
+}
+
+// CountGrouping groups the digit count in the left margin into thousands
+// if on is true. For example, 1000000 displays as 1,000,000. CountGrouping
+// has no effect if CountFormat is used.
+func CountGrouping(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countGrouping = on
+	})
+}
+
+// CountGroupingSeparator sets the separator that CountGrouping uses. The
+// default is a comma.
+func CountGroupingSeparator(sep string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.countGroupingSeparator = sep
 	})
 }
 
 // MissingDigit sets the character to represent a missing digit.
+// MissingDigit overrides any earlier MissingDigits option.
 func MissingDigit(missingDigit rune) Option {
 	return optionFunc(func(p *printerSettings) {
 		p.missingDigit = missingDigit
+		p.missingDigits = ""
+	})
+}
+
+// MissingDigits works like MissingDigit except that it sets a string such
+// as "--" or "??" to represent each missing digit. The string takes the
+// place of one digit: it counts as one digit toward DigitsPerRow and
+// DigitsPerColumn. When s is more than one rune wide, Fprint and Fwrite
+// pad every digit on the left with spaces to the same width so that
+// columns still line up. An empty s means use the default missing digit.
+func MissingDigits(s string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.missingDigits = s
+	})
+}
+
+// MissingDigitFunc makes the printer call fn with the position of each
+// missing digit to get the rune to show for it. MissingDigitFunc takes
+// precedence over MissingDigit and MissingDigits. Columns line up as long
+// as fn returns runes as wide as a digit. Fhtml and Flatex also use fn,
+// but Fcsv does not. Passing nil turns MissingDigitFunc off.
+func MissingDigitFunc(fn func(posit int) rune) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.missingDigitFunc = fn
+	})
+}
+
+// UpperCase displays digits 10 through 35 as 'A' through 'Z' instead of
+// 'a' through 'z' if on is true. UpperCase changes only these alphabetic
+// digits, so it has no effect on the digits that CustomDigits sets. The
+// default is off.
+func UpperCase(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.upperCase = on
+	})
+}
+
+// CustomDigits sets the runes used to display the digits 0-9. runes[d] is
+// displayed for digit d. Digits 10 and above, which are possible only with
+// the Radix option, display the same as they would without CustomDigits.
+func CustomDigits(runes [10]rune) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.customDigits = runes[:]
+	})
+}
+
+// Radix sets the base of the digits to print. base must be between 2 and 36.
+// Digits 10 through 35 display as 'a' through 'z' or as 'A' through 'Z'
+// with UpperCase. The default is 10.
+// Printing reports an error if base is out of range or if a digit is not
+// between 0 and base-1.
+func Radix(base int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.radix = base
 	})
 }
 
 // TrailingLF adds a trailing line feed to what is printed if on is true.
+// Line feeds otherwise go only between rows, so if on is false, the last
+// row has no line feed after it no matter how many rows there are, and
+// what is printed never ends with a line feed unless the options
+// themselves contain one, for instance a TruncationMarker ending in a line
+// feed. The number of bytes written counts the trailing line feed when
+// there is one. Fprint defaults to false; Fwrite defaults to true.
 func TrailingLF(on bool) Option {
 	return optionFunc(func(p *printerSettings) {
 		p.trailingLineFeed = on
 	})
 }
 
+// LineEnding sets the line ending that goes between rows, after the last
+// row with TrailingLF, and at the end of any other line such as the one
+// Ruler prints. For example, "\r\n" gives Windows line endings. Fcsv uses
+// "\r\n" line endings if sep is "\r\n". The default is "\n".
+func LineEnding(sep string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.lineEnding = sep
+	})
+}
+
+// DecimalSeparator sets the decimal separator that LeadingDecimal uses.
+// Unless set with MissingDigit, the missing digit is the same as the decimal
+// separator. The default is a period (.).
+func DecimalSeparator(sep rune) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.decimalSeparator = sep
+	})
+}
+
 // LeadingDecimal prints "0." before the first digit if on is true.
 func LeadingDecimal(on bool) Option {
 	return optionFunc(func(p *printerSettings) {
@@ -87,6 +493,347 @@ func LeadingDecimal(on bool) Option {
 	})
 }
 
+// Alignment says how to line up the digits in a row that has room for
+// more digits than it has.
+type Alignment int
+
+const (
+	// AlignLeft starts each row at the left margin and groups the digits
+	// of each row into columns starting from the left. AlignLeft is the
+	// default.
+	AlignLeft Alignment = iota
+
+	// AlignRight ends each row at the right edge of a full row and groups
+	// the digits of each row into columns starting from the right, the way
+	// thousands separators group the digits of an integer. A last row
+	// that is not full gets blank space on the left.
+	AlignRight
+)
+
+// Align sets how to line up the digits within each row. Align has no
+// effect with ColumnOffset, ColumnWindow, GapFill(GapSkip), CollapseGaps,
+// or Limit. Default is AlignLeft.
+func Align(alignment Alignment) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.alignment = alignment
+	})
+}
+
+// GapMode says how to print gaps in a sequence of digits.
+type GapMode int
+
+const (
+	// GapMissingDigit prints each missing digit as the rune that MissingDigit
+	// sets. When the digit count is shown, rows with only missing digits are
+	// left out. GapMissingDigit is the default.
+	GapMissingDigit GapMode = iota
+
+	// GapZero works like GapMissingDigit except that it prints each missing
+	// digit as 0.
+	GapZero
+
+	// GapSkip leaves out missing digits entirely so that the digits on
+	// either side of a gap print next to each other. The digit count in the
+	// left margin then shows the position of each digit among the printed
+	// digits rather than its position in the sequence.
+	GapSkip
+)
+
+// GapFill sets how to print gaps in a sequence of digits.
+func GapFill(mode GapMode) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.gapMode = mode
+	})
+}
+
+// CollapseGaps prints each run of missing digits as a single missing
+// digit marker if on is true. Like GapFill(GapSkip), CollapseGaps groups
+// only the digits that are there into columns and rows, so a marker takes
+// up no column slot and columns no longer line up with positions. The
+// marker goes right before the first digit after the gap, which may put
+// it at the start of a row. Unlike GapFill(GapSkip), the digit count in
+// the left margin shows the real position of the first digit of each
+// row. CollapseGaps overrides GapFill. Default is off.
+func CollapseGaps(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.collapseGaps = on
+	})
+}
+
+// Strict makes printing fail with an error wrapping ErrMissingDigit the
+// first time it comes to a missing digit that it would print. The error
+// includes the position of the missing digit. Printing stops there. Strict
+// has no effect with GapFill(GapSkip) or CollapseGaps as these print no
+// missing digits. Default is off.
+func Strict(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.strict = on
+	})
+}
+
+// TabColumns lays out the digits for pasting into a spreadsheet if on is
+// true so that each column of digits lands in its own cell. TabColumns
+// separates columns with a tab and leaves out the left margin unless
+// options explicitly include ShowCount(true), in which case a tab follows
+// the digit count. TabColumns turns off LeadingDecimal, Ruler,
+// ColumnHeaders, FitWidth, and Align(AlignRight) as these line things up
+// with spaces. Default is off.
+func TabColumns(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.tabColumns = on
+	})
+}
+
+// Limit stops printing after the first n digits no matter how many
+// positions there are to print. When Limit cuts printing short, the
+// TruncationMarker, if any, comes right after the last digit printed.
+// Limit does not change the width of the left margin which still depends
+// on all the positions. Zero or negative means no limit which is the
+// default.
+func Limit(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.limit = n
+	})
+}
+
+// MaxRows stops printing after the first n rows no matter how many
+// positions there are to print. When MaxRows cuts printing short, the
+// TruncationMarker, if any, comes right after the last digit of the last
+// row printed. Rows that have only missing digits and that printing
+// leaves out do not count toward n. MaxRows has no effect without
+// separate rows. Like Limit, MaxRows does not change the width of the
+// left margin. Zero or negative means no limit which is the default.
+func MaxRows(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.maxRows = n
+	})
+}
+
+// TruncationMarker sets text such as "..." to print right after the last
+// digit when there are more digits past the positions printed. Only Fprint,
+// Sprint, and Print use TruncationMarker as the others always print all the
+// digits. They check for more digits by asking for the digit at the end
+// position. Appender.Finish also writes TruncationMarker but only when
+// Limit or MaxRows cuts printing short. The default is no truncation
+// marker.
+func TruncationMarker(text string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.truncationMarker = text
+	})
+}
+
+// MarkdownFence encloses what is printed in a Markdown code fence with
+// lang as the info string. lang may be empty. The closing fence goes on its
+// own line; TrailingLF adds a line feed after it. If what is printed could
+// contain backticks, for instance because of CustomDigits, the fence is
+// made longer than any run of backticks inside it, and nothing is written
+// until printing finishes.
+func MarkdownFence(lang string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.markdownFence = true
+		p.fenceLang = lang
+	})
+}
+
+// Reverse prints digits from the highest position to the lowest if on is
+// true. The digit count in the left margin then shows the position of the
+// first digit in each row which decreases from row to row. Reverse works
+// only with Fwrite, Swrite, and Write as it needs the Backward method of
+// Writable. Other functions ignore it.
+func Reverse(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.reverse = on
+	})
+}
+
+// LeadingInteger prints the decimal separator after the first count digits
+// instead of before the first digit. When count is positive, LeadingDecimal
+// has no effect, and no decimal separator prints if there are count or
+// fewer digits. Zero or negative means no integer digits, the default.
+func LeadingInteger(count int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.leadingInteger = count
+	})
+}
+
+// Sign prints a minus sign before the first digit, or before the leading
+// decimal point, if negative is true. Only the first row gets the sign;
+// the other rows get blank space in its place so that the digits still
+// line up. Default is false.
+func Sign(negative bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.negative = negative
+	})
+}
+
+// IntegerWidth lines up the decimal separator that LeadingInteger adds as
+// if there were width integer digits by indenting each row with blank
+// space. Use IntegerWidth with the same width when printing several
+// numbers with different numbers of integer digits one above the other so
+// that their decimal separators line up. IntegerWidth has no effect
+// without LeadingInteger or when width is not more than the number of
+// integer digits. The default is 0.
+func IntegerWidth(width int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.integerWidth = width
+	})
+}
+
+// Repeat marks the digits at positions from start up to but not
+// including end as the digits that repeat by putting a line over each one.
+// The line is a combining character that takes up no extra space, so
+// Repeat does not change how the digits line up. Use DetectRepeat to find
+// the digits that repeat. The default is to mark no digits.
+func Repeat(start, end int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.repeatStart = start
+		p.repeatEnd = end
+	})
+}
+
+// DecimalColumn puts the decimal separator that LeadingInteger adds at
+// the zero based column col of the output by indenting each row with
+// blank space. Columns count from the start of the line, so they include
+// the left margin and any Indent. DecimalColumn overrides IntegerWidth.
+// If the integer digits are too wide for the decimal separator to go at
+// col, printing fails with an error. DecimalColumn has no effect without
+// LeadingInteger. Zero or negative means no set column, the default.
+func DecimalColumn(col int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.decimalColumn = col
+	})
+}
+
+// Highlight highlights the digits at the positions in the keys of
+// positions using ANSI escape codes. The value for each position contains
+// the SGR parameters for that digit such as "31" for red or "1;32" for bold
+// green. Each highlighted digit is followed by a reset. The escape codes
+// take no room in a terminal, so columns still line up.
+func Highlight(positions map[int]string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.highlight = positions
+	})
+}
+
+// HighlightLast highlights the last digit printed using the ANSI SGR
+// parameters in sgr such as "1;32" for bold green. Since which digit is
+// last is known only once printing finishes, the last digit printed so far
+// is written only when the next digit comes or printing finishes.
+// HighlightLast overrides Highlight for the last digit. An empty sgr means
+// no highlighting, the default.
+func HighlightLast(sgr string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.highlightLast = sgr
+	})
+}
+
+// Checksum writes the value of each digit printed to h as a single byte
+// so that h holds a checksum of the digits once printing finishes. Missing
+// digits are not written to h. Call h.Sum after printing to get the
+// checksum, or use StreamPrinter.Checksum when using a StreamPrinter.
+func Checksum(h hash.Hash) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.checksum = h
+	})
+}
+
+// AppendChecksumLine writes a line such as "# checksum: 1a2b3c4d" after
+// everything else if on is true so that the output carries the hex digest
+// of the hash that Checksum sets. The checksum line goes after any
+// trailing line feed and closing MarkdownFence, and when there is a
+// trailing line feed, the checksum line gets one too. The bytes written
+// include the checksum line. AppendChecksumLine has no effect without
+// Checksum. Default is off.
+func AppendChecksumLine(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.appendChecksumLine = on
+	})
+}
+
+// NoColor turns off all ANSI escape codes such as those from Highlight if
+// on is true. Use NoColor when writing to something other than a terminal.
+func NoColor(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.noColor = on
+	})
+}
+
+// OnRow makes the printer call fn with each row after formatting it but
+// before writing it. rowIndex is the 0-based index of the row; text is the
+// row including any count and column separators but excluding the line
+// feed and row separator that come between rows. OnRow is useful for
+// capturing rows for custom rendering.
+func OnRow(fn func(rowIndex int, text string)) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.onRow = fn
+	})
+}
+
+// OnDigit makes the printer call fn with the position and value of each
+// digit it consumes in the order it consumes them, which is the order
+// that the Printable or Writable yields them. The printer calls fn before
+// it prints the digit. The printer does not call fn for missing digits,
+// for digits past a Limit, or after an error such as a digit out of range
+// for the radix. Like Checksum, OnDigit sees digits that ColumnOffset or
+// ColumnWindow hide.
+func OnDigit(fn func(posit, digit int)) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.onDigit = fn
+	})
+}
+
+// OnProgress makes the printer call fn after every n digits it prints
+// and once more when it finishes without error. done is one past the
+// position of the last digit printed, and total is one past the last
+// position to print, so total - done digits remain. When printing
+// finishes, done equals total. Zero or negative n means call fn only when
+// printing finishes.
+func OnProgress(n int, fn func(done, total int)) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.progressInterval = n
+		p.onProgress = fn
+	})
+}
+
+// Indent adds n spaces at the start of every line written including the
+// ruler, column headers, blank lines between rows, and Markdown fences.
+// Unlike RowPrefix, Indent does not change the left margin. Zero or
+// negative means no indent, the default.
+func Indent(n int) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.indent = n
+	})
+}
+
+// RowPrefix sets text such as "// " to print at the start of each row
+// right after the digit count. The ruler and column headers line up with
+// the digits after the prefix, but they do not get the prefix themselves.
+// The default is no prefix.
+func RowPrefix(prefix string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.rowPrefix = prefix
+	})
+}
+
+// RowSuffix sets text to print at the end of each row right after the
+// last digit. The default is no suffix.
+func RowSuffix(suffix string) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.rowSuffix = suffix
+	})
+}
+
+// NoBuffer writes straight to the writer without buffering if on is true.
+// Use NoBuffer when the writer is already buffered but has no Flush
+// method. Writers with a Flush method, such as a bufio.Writer, never get
+// an extra buffer. Writing without a buffer to a writer that is not
+// buffered can be slow. Default is off.
+func NoBuffer(on bool) Option {
+	return optionFunc(func(p *printerSettings) {
+		p.noBuffer = on
+	})
+}
+
 func bufferSize(size int) Option {
 	return optionFunc(func(p *printerSettings) {
 		p.bufferSize = size
@@ -96,56 +843,107 @@ func bufferSize(size int) Option {
 // Fprint prints digits of s to w. Unless using advanced functionality,
 // prefer Fwrite, Write, and Swrite to Fprint, Print, and Sprint.
 // Fprint returns the number of bytes written and any error encountered.
-// p contains the positions of the digits to print.
+// p contains the positions of the digits to print. Positions in p for
+// which s has no digits display as missing digits.
 // For options, the default is 50 digits per row, 5 digits per column,
-// show digit count, period (.) for missing digits, don't write a trailing
-// line feed, and show the leading decimal point.
+// columns separated by a space, show digit count, period (.) for the
+// decimal separator and for missing digits, don't write a trailing line
+// feed, and show the leading decimal point.
 func Fprint(w io.Writer, s Printable, p Positions, options ...Option) (
 	written int, err error) {
-	settings := &printerSettings{
-		digitsPerRow:    50,
-		digitsPerColumn: 5,
-		showCount:       true,
-		missingDigit:    '.',
-		leadingDecimal:  true,
-	}
-	printer := newPrinter(w, p.End(), mutateSettings(options, settings))
-	fromSequenceWithPositions(s, p, printer)
-	printer.Finish()
-	return printer.BytesWritten(), printer.Err()
+	return FprintContext(context.Background(), w, s, p, options...)
+}
+
+// FprintContext works like Fprint except that it stops printing if ctx is
+// done. In that case, FprintContext flushes what it printed so far and
+// returns ctx.Err().
+func FprintContext(
+	ctx context.Context,
+	w io.Writer,
+	s Printable,
+	p Positions,
+	options ...Option) (written int, err error) {
+	if len(options) == 0 {
+		return fprint(ctx, w, s, p, defaultFormatter.printSettings)
+	}
+	settings := printSettings(options)
+	settings.reverse = false
+	return fprint(ctx, w, s, p, settings)
 }
 
 // Fwrite writes all the digits of s to w. Fwrite returns the number of bytes
 // written and any error encountered. For options, the default is 50 digits
-// per row, 5 digits per column, show digit count, period (.) for missing
-// digits, write a trailing line feed, and don't show the leading decimal
-// point.
+// per row, 5 digits per column, columns separated by a space, show digit
+// count, period (.) for the decimal separator and for missing digits, write
+// a trailing line feed, and don't show the leading decimal point.
 func Fwrite(w io.Writer, s Writable, options ...Option) (
 	written int, err error) {
-	settings := &printerSettings{
-		digitsPerRow:     50,
-		digitsPerColumn:  5,
-		showCount:        true,
-		missingDigit:     '.',
-		trailingLineFeed: true,
+	return FwriteContext(context.Background(), w, s, options...)
+}
+
+// FwriteContext works like Fwrite except that it stops writing if ctx is
+// done. In that case, FwriteContext flushes what it wrote so far and
+// returns ctx.Err().
+func FwriteContext(
+	ctx context.Context, w io.Writer, s Writable, options ...Option) (
+	written int, err error) {
+	if len(options) == 0 {
+		return fwrite(ctx, w, s, endOf(s), defaultFormatter.writeSettings)
 	}
-	printer := newPrinter(w, endOf(s), mutateSettings(options, settings))
-	fromIterator(s.All(), printer)
-	printer.Finish()
-	return printer.BytesWritten(), printer.Err()
+	return fwrite(ctx, w, s, endOf(s), writeSettings(options))
+}
+
+// Ftee works like Fwrite except that it writes to all of writers at once
+// so that it iterates over s only once. Ftee returns the number of bytes
+// written to each writer and the first error encountered. Ftee stops at
+// the first error.
+func Ftee(writers []io.Writer, s Writable, options ...Option) (
+	written int, err error) {
+	return Fwrite(io.MultiWriter(writers...), s, options...)
 }
 
 // Sprint works like Fprint and prints digits of s to a string.
 func Sprint(s Printable, p Positions, options ...Option) string {
 	var builder strings.Builder
+	builder.Grow(printSettings(options).growSize(p))
 	Fprint(&builder, s, p, options...)
 	return builder.String()
 }
 
+// SprintE works like Sprint except that it also returns the number of
+// bytes printed and any error encountered such as a digit out of range
+// for the radix or a missing digit in Strict mode. On error, the returned
+// string has what was printed before the error.
+func SprintE(s Printable, p Positions, options ...Option) (
+	string, int, error) {
+	var builder strings.Builder
+	builder.Grow(printSettings(options).growSize(p))
+	written, err := Fprint(&builder, s, p, options...)
+	return builder.String(), written, err
+}
+
+// growSize returns how many bytes to reserve up front when printing the
+// digits at positions p to a string. growSize estimates from the number
+// of digits to print rather than from p.End(), stops counting digits where
+// Limit or MaxRows would, and never returns more than maxGrowSize.
+func (p *printerSettings) growSize(positions Positions) int {
+	count := positions.Count()
+	if p.limit > 0 {
+		count = min(count, p.limit)
+	}
+	if p.maxRows > 0 && p.digitsPerRow > 0 {
+		count = min(count, p.maxRows*p.digitsPerRow)
+	}
+	return min(p.estimateSize(count), maxGrowSize)
+}
+
 // Swrite works like Fwrite and writes all the digits of s to returned string.
 func Swrite(s Writable, options ...Option) string {
+	settings := writeSettings(options)
+	end := endOf(s)
 	var builder strings.Builder
-	Fwrite(&builder, s, options...)
+	builder.Grow(settings.estimateSize(end))
+	fwrite(context.Background(), &builder, s, end, settings)
 	return builder.String()
 }
 
@@ -161,6 +959,68 @@ func Write(s Writable, options ...Option) (
 	return Fwrite(os.Stdout, s, options...)
 }
 
+// Len returns the number of digits in s. Len calls the Backward method of
+// s once to find the position of the last digit. Len is only as fast as
+// getting the first value from Backward which for some Writables may
+// require computing every digit.
+func Len(s Writable) int {
+	return endOf(s)
+}
+
+// LenInRange returns the number of digits of s present at the positions in
+// p. Unlike p.End(), LenInRange does not count positions where s has no
+// digit. LenInRange has to iterate over every digit of s in p, so its cost
+// is the same as printing those digits.
+func LenInRange(s Printable, p Positions) int {
+	result := 0
+	for pr := range p.All() {
+		for range s.AllInRange(pr.Start, pr.End) {
+			result++
+		}
+	}
+	return result
+}
+
+// EstimateSize returns about how many bytes Fprint writes when printing
+// the digits at positions 0 up to end with options. EstimateSize is
+// useful for sizing buffers ahead of time. Because Fprint skips rows
+// containing only missing digits when the digit count is shown,
+// EstimateSize may overestimate for sparse Positions.
+func EstimateSize(end int, options ...Option) int {
+	return printSettings(options).estimateSize(end)
+}
+
+func printSettings(options []Option) *printerSettings {
+	settings := &printerSettings{
+		digitsPerRow:     50,
+		digitsPerColumn:  5,
+		columnSeparator:  " ",
+		groupSeparator:   "  ",
+		showCount:        true,
+		decimalSeparator: '.',
+		radix:            10,
+		leadingDecimal:   true,
+		gutter:           "    ",
+		pageSeparator:    "\f",
+	}
+	return mutateSettings(options, settings)
+}
+
+func writeSettings(options []Option) *printerSettings {
+	settings := &printerSettings{
+		digitsPerRow:     50,
+		digitsPerColumn:  5,
+		columnSeparator:  " ",
+		groupSeparator:   "  ",
+		showCount:        true,
+		decimalSeparator: '.',
+		radix:            10,
+		trailingLineFeed: true,
+		pageSeparator:    "\f",
+	}
+	return mutateSettings(options, settings)
+}
+
 func endOf(s Writable) int {
 	for index := range s.Backward() {
 		return index + 1
@@ -168,17 +1028,68 @@ func endOf(s Writable) int {
 	return 0
 }
 
-func fromSequenceWithPositions(s Printable, p Positions, printer *printer) {
+func hasDigitAt(s Printable, posit int) bool {
+	for range s.AllInRange(posit, posit+1) {
+		return true
+	}
+	return false
+}
+
+func fprint(
+	ctx context.Context,
+	w io.Writer,
+	s Printable,
+	p Positions,
+	settings *printerSettings) (written int, err error) {
+	printer := newPrinter(w, p.End(), settings)
+	fromSequenceWithPositions(ctx, s, p, printer)
+	if settings.truncationMarker != "" &&
+		(printer.truncated() || hasDigitAt(s, p.End())) {
+		printer.Append(settings.truncationMarker)
+	}
+	printer.Finish()
+	settings.releaseWriter(printer.writer)
+	return printer.BytesWritten(), printer.Err()
+}
+
+func fwrite(
+	ctx context.Context,
+	w io.Writer,
+	s Writable,
+	end int,
+	settings *printerSettings) (written int, err error) {
+	printer := newPrinter(w, end, settings)
+	if settings.reverse {
+		fromIterator(ctx, s.Backward(), printer)
+	} else {
+		fromIterator(ctx, s.All(), printer)
+	}
+	printer.Finish()
+	settings.releaseWriter(printer.writer)
+	return printer.BytesWritten(), printer.Err()
+}
+
+func fromSequenceWithPositions(
+	ctx context.Context, s Printable, p Positions, printer *printer) {
 	for pr := range p.All() {
-		fromIterator(s.AllInRange(pr.Start, pr.End), printer)
+		fromIterator(ctx, s.AllInRange(pr.Start, pr.End), printer)
 	}
 }
 
-func fromIterator(it iter.Seq2[int, int], printer *printer) {
+func fromIterator(
+	ctx context.Context, it iter.Seq2[int, int], printer digitConsumer) {
 	if !printer.CanConsume() {
 		return
 	}
+	count := 0
 	for posit, digit := range it {
+		if count%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				printer.Abort(err)
+				return
+			}
+		}
+		count++
 		printer.Consume(posit, digit)
 		if !printer.CanConsume() {
 			return
@@ -186,6 +1097,12 @@ func fromIterator(it iter.Seq2[int, int], printer *printer) {
 	}
 }
 
+type digitConsumer interface {
+	CanConsume() bool
+	Consume(posit, digit int)
+	Abort(err error)
+}
+
 type optionFunc func(p *printerSettings)
 
 func (o optionFunc) mutate(p *printerSettings) {
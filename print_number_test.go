@@ -1,8 +1,13 @@
 package numprint
 
 import (
+	"context"
 	"errors"
+	"io"
 	"iter"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -263,6 +268,168 @@ func TestPrinterNegative(t *testing.T) {
 	assert.Equal(t, "", actual)
 }
 
+func TestPrinterCustomDigits(t *testing.T) {
+	fullWidth := [10]rune{
+		'０', '１', '２', '３', '４', '５', '６', '７', '８', '９'}
+	w := &maxBytesWriter{maxBytes: 1000}
+	var pb PositionsBuilder
+	n, err := Fprint(
+		w,
+		newFakeNumber(),
+		pb.AddRange(0, 7).AddRange(9, 12).Build(),
+		DigitsPerColumn(5),
+		CustomDigits(fullWidth))
+	assert.NoError(t, err)
+
+	// 2 bytes for "0.", 10 digits 3 bytes each, 2 missing digits 1 byte
+	// each, and 2 column spaces.
+	assert.Equal(t, 2+10*3+2+2, n)
+	actual := Sprint(
+		newFakeNumber(),
+		pb.AddRange(0, 7).AddRange(9, 12).Build(),
+		DigitsPerColumn(5),
+		CustomDigits(fullWidth))
+	assert.Equal(t, "0.１２３４５ ６７..０ １２", actual)
+}
+
+func TestPrinterRadix(t *testing.T) {
+	hex := fakeDigits{15, 14, 0, 9, 10, 35}
+	actual := Sprint(hex, UpTo(6), Radix(36), DigitsPerColumn(3))
+	assert.Equal(t, "0.fe0 9az", actual)
+	n, err := Fprint(io.Discard, hex, UpTo(6), Radix(16))
+	assert.Equal(t, 7, n)
+	assert.ErrorContains(t, err, "digit 35 at position 5")
+	_, err = Fprint(io.Discard, hex, UpTo(6), Radix(37))
+	assert.Error(t, err)
+	_, err = Fprint(io.Discard, hex, UpTo(6), Radix(1))
+	assert.Error(t, err)
+	_, err = Fprint(io.Discard, hex, UpTo(6))
+	assert.ErrorContains(t, err, "digit 15 at position 0")
+}
+
+func TestPrinterLeftAlign(t *testing.T) {
+	actual := Sprint(
+		newFakeNumber(),
+		UpTo(110),
+		DigitsPerRow(50),
+		DigitsPerColumn(10),
+		RightAlign(false))
+	expected := `   0.1234567890 1234567890 1234567890 1234567890 1234567890
+50   1234567890 1234567890 1234567890 1234567890 1234567890
+100  1234567890`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrinterCountGrouping(t *testing.T) {
+	var pb PositionsBuilder
+	positions := pb.AddRange(0, 2).AddRange(999998, 1000002).Build()
+	actual := Sprint(
+		newFakeNumber(),
+		positions,
+		DigitsPerRow(2),
+		DigitsPerColumn(0),
+		CountGrouping(true))
+	expected := `         0.12
+  999,998  90
+1,000,000  12`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		newFakeNumber(),
+		positions,
+		DigitsPerRow(2),
+		DigitsPerColumn(0),
+		LeadingDecimal(false),
+		CountGrouping(true),
+		CountGroupingSeparator("_"),
+		RightAlign(false),
+		CountWidth(12))
+	expected = `0             12
+999_998       90
+1_000_000     12`
+	assert.Equal(t, expected, actual)
+}
+
+func TestPrinterContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var builder strings.Builder
+	n, err := FprintContext(
+		ctx, &builder, &cancellingNumber{cancel: cancel, at: 3000}, UpTo(10000))
+	assert.Equal(t, context.Canceled, err)
+	assert.Equal(t, builder.Len(), n)
+
+	// We check the context every 1024 digits so we print up to position
+	// 3071.
+	assert.True(
+		t,
+		strings.HasSuffix(
+			builder.String(), "\n3050  12345 67890 12345 67890 12"))
+	n, err = FprintContext(ctx, &builder, newFakeNumber(), UpTo(10))
+	assert.Equal(t, context.Canceled, err)
+	assert.Zero(t, n)
+}
+
+func TestPrinterDecimalSeparator(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(0, 3).AddRange(5, 15).Build()
+	actual := Sprint(
+		newFakeNumber(), p, DigitsPerRow(10), DecimalSeparator(','))
+	expected := `  0,123,, 67890
+10  12345`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		newFakeNumber(),
+		p,
+		DigitsPerRow(10),
+		DecimalSeparator('·'),
+		MissingDigit('-'))
+	expected = `  0·123-- 67890
+10  12345`
+	assert.Equal(t, expected, actual)
+	n, err := Fprint(
+		io.Discard, newFakeNumber(), p, DigitsPerRow(10), DecimalSeparator('·'))
+	assert.NoError(t, err)
+
+	// 25 characters. The decimal separator and the 2 missing digits take an
+	// extra byte each.
+	assert.Equal(t, 25+3, n)
+}
+
+func TestPrinterLeadingInteger(t *testing.T) {
+	actual := Sprint(
+		newFakeNumber(), UpTo(25), DigitsPerRow(10), LeadingInteger(3))
+	expected := ` 0  123.45 67890
+10  12345 67890
+20  12345`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		newFakeNumber(), UpTo(25), DigitsPerRow(10), LeadingInteger(10))
+	expected = ` 0  12345 67890.
+10  12345 67890
+20  12345`
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		newFakeNumber(), UpTo(25), DigitsPerRow(10), LeadingInteger(0))
+	expected = `  0.12345 67890
+10  12345 67890
+20  12345`
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, "123", Sprint(
+		newFakeNumber(), UpTo(3), ShowCount(false), LeadingInteger(3)))
+	assert.Equal(t, "1.23", Sprint(
+		newFakeNumber(), UpTo(3), ShowCount(false), LeadingInteger(1)))
+}
+
+func TestPrinterHighlight(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(0, 3).AddRange(5, 7).Build()
+	highlight := map[int]string{1: "31", 5: "1;32", 4: "33"}
+	actual := Sprint(newFakeNumber(), p, Highlight(highlight))
+	expected := "0.1\x1b[31m2\x1b[0m3.. \x1b[1;32m6\x1b[0m7"
+	assert.Equal(t, expected, actual)
+	actual = Sprint(newFakeNumber(), p, Highlight(highlight), NoColor(true))
+	assert.Equal(t, "0.123.. 67", actual)
+}
+
 func TestPrinterCountBytes(t *testing.T) {
 	w := &maxBytesWriter{maxBytes: 100000}
 
@@ -329,6 +496,92 @@ func (m *maxBytesWriter) Write(p []byte) (n int, err error) {
 	return diff, errors.New("Ran out of space")
 }
 
+type cancellingNumber struct {
+	fakeNumber
+	cancel func()
+	at     int
+}
+
+func (c *cancellingNumber) AllInRange(start, end int) iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for posit, digit := range c.fakeNumber.AllInRange(start, end) {
+			if posit == c.at {
+				c.cancel()
+			}
+			if !yield(posit, digit) {
+				return
+			}
+		}
+	}
+}
+
+type sparseNumber struct {
+	p    Positions
+	fake fakeNumber
+}
+
+func (s *sparseNumber) All() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for pr := range s.p.All() {
+			for posit, digit := range s.fake.AllInRange(pr.Start, pr.End) {
+				if !yield(posit, digit) {
+					return
+				}
+			}
+		}
+	}
+}
+
+func (s *sparseNumber) Backward() iter.Seq2[int, int] {
+	ranges := slices.Collect(s.p.All())
+	return func(yield func(int, int) bool) {
+		for i := len(ranges) - 1; i >= 0; i-- {
+			pr := ranges[i]
+			for posit, digit := range s.fake.BackwardInRange(pr.Start, pr.End) {
+				if !yield(posit, digit) {
+					return
+				}
+			}
+		}
+	}
+}
+
+type countingNumber struct {
+	fakeNumberRange
+	allCalls int
+}
+
+func (c *countingNumber) All() iter.Seq2[int, int] {
+	c.allCalls++
+	return c.fakeNumberRange.All()
+}
+
+type fakeDigits []int
+
+func (f fakeDigits) AllInRange(start, end int) iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for i := max(start, 0); i < min(end, len(f)); i++ {
+			if !yield(i, f[i]) {
+				return
+			}
+		}
+	}
+}
+
+func (f fakeDigits) All() iter.Seq2[int, int] {
+	return f.AllInRange(0, len(f))
+}
+
+func (f fakeDigits) Backward() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for i := len(f) - 1; i >= 0; i-- {
+			if !yield(i, f[i]) {
+				return
+			}
+		}
+	}
+}
+
 type fakeNumber struct {
 }
 
@@ -377,3 +630,871 @@ func (f *fakeNumberRange) All() iter.Seq2[int, int] {
 func (f *fakeNumberRange) Backward() iter.Seq2[int, int] {
 	return f.fake.BackwardInRange(f.Start, f.End)
 }
+
+func TestLenInRange(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(5, 10).AddRange(15, 30)
+	p := pb.Build()
+	assert.Equal(t, 20, LenInRange(fakeNumber{}, p))
+	assert.Equal(t, 10, LenInRange(newFakeNumberRange(0, 20), p))
+	assert.Equal(t, 0, LenInRange(fakeNumber{}, Positions{}))
+}
+
+func TestEstimateSize(t *testing.T) {
+	optionSets := [][]Option{
+		nil,
+		{DigitsPerRow(0)},
+		{DigitsPerRow(7), DigitsPerColumn(3), RowSeparator("\n")},
+		{ShowCount(false), LeadingDecimal(false)},
+		{DigitsPerRow(10), ColumnOffset(3), ColumnWindow(4)},
+		{DigitsPerRow(10), LeadingInteger(3), CountGrouping(true)},
+		{Highlight(map[int]string{3: "1", 80: "1;31"})},
+		{DigitsPerRow(12), Ruler(true)},
+		{MarkdownFence("text")},
+		{DigitsPerRow(7), LineEnding("\r\n"), Ruler(true), MarkdownFence("")},
+		{Verbose(true)},
+		{Verbose(true), DigitsPerRow(0)},
+		{Verbose(true), DigitsPerRow(10), ColumnOffset(2), ColumnWindow(3)},
+	}
+	for _, end := range []int{0, 1, 49, 50, 51, 1234} {
+		for _, options := range optionSets {
+			assert.Equal(
+				t,
+				len(Sprint(fakeNumber{}, UpTo(end), options...)),
+				EstimateSize(end, options...))
+		}
+	}
+}
+
+func TestSprintGrowSize(t *testing.T) {
+	settings := printSettings(nil)
+	p := Between(200_000_000, 200_000_010)
+	assert.Less(t, settings.growSize(p), 100)
+	actual := Sprint(fakeNumber{}, p)
+	assert.Equal(t, "200000000  12345 67890", actual)
+	settings = printSettings([]Option{Limit(100)})
+	assert.Equal(t, settings.estimateSize(100), settings.growSize(UpTo(5000)))
+	settings = printSettings([]Option{DigitsPerRow(10), MaxRows(2)})
+	assert.Equal(t, settings.estimateSize(20), settings.growSize(UpTo(5000)))
+	settings = printSettings(nil)
+	assert.Equal(t, maxGrowSize, settings.growSize(UpTo(1_000_000_000)))
+}
+
+func TestPrintGapFill(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(2, 5).AddRange(8, 10).AddRange(25, 27)
+	p := pb.Build()
+	number := fakeNumber{}
+	assert.Equal(
+		t,
+		`  0...3 45. ..9 0
+20  ... ..6 7`,
+		Sprint(number, p, DigitsPerRow(10), DigitsPerColumn(3)))
+	assert.Equal(
+		t,
+		`  0.003 450 009 0
+20  000 006 7`,
+		Sprint(
+			number,
+			p,
+			DigitsPerRow(10),
+			DigitsPerColumn(3),
+			GapFill(GapZero)))
+	assert.Equal(
+		t,
+		"  0.345 906 7",
+		Sprint(
+			number,
+			p,
+			DigitsPerRow(10),
+			DigitsPerColumn(3),
+			GapFill(GapSkip)))
+}
+
+func TestPrintCollapseGaps(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(0, 4).AddRange(7, 12).AddRange(30, 36).Build()
+	options := []Option{
+		DigitsPerRow(4), DigitsPerColumn(2), MissingDigit('?'),
+		CollapseGaps(true)}
+	expected := `  0.12 34
+ 7  ?89 01
+11  2?1 23
+33  45 6`
+	assert.Equal(t, expected, Sprint(newFakeNumber(), p, options...))
+	assert.Equal(
+		t,
+		"  0.1234?8 9012?1\n31  23456",
+		Sprint(
+			newFakeNumber(), p, DigitsPerRow(10), MissingDigit('?'),
+			GapFill(GapZero), CollapseGaps(true)))
+	assert.Equal(
+		t,
+		"0.?45678",
+		Sprint(
+			newFakeNumber(), Between(3, 8), ShowCount(false),
+			MissingDigit('?'), CollapseGaps(true)))
+}
+
+func TestPrintTruncationMarker(t *testing.T) {
+	assert.Equal(
+		t,
+		"0.12345 67890 12...",
+		Sprint(
+			fakeNumber{},
+			UpTo(12),
+			DigitsPerRow(0),
+			ShowCount(false),
+			TruncationMarker("...")))
+	number := newFakeNumberRange(0, 12)
+	assert.Equal(
+		t,
+		"0.12345 67890 12",
+		Sprint(
+			number,
+			UpTo(12),
+			DigitsPerRow(0),
+			ShowCount(false),
+			TruncationMarker("...")))
+	assert.Equal(
+		t,
+		"0.12345 67...",
+		Sprint(
+			number,
+			UpTo(7),
+			DigitsPerRow(0),
+			ShowCount(false),
+			TruncationMarker("...")))
+	assert.Equal(
+		t,
+		"0.12345 67",
+		Sprint(number, UpTo(7), DigitsPerRow(0), ShowCount(false)))
+}
+
+func TestPrintHighlightLastTruncationMarker(t *testing.T) {
+	actual := Sprint(
+		fakeNumber{},
+		UpTo(3),
+		ShowCount(false),
+		TruncationMarker("..."),
+		HighlightLast("1"))
+	assert.Equal(t, "0.12\x1b[1m3\x1b[0m...", actual)
+}
+
+func TestPrintCountThreshold(t *testing.T) {
+	assert.Equal(
+		t,
+		"0.12345 67890\n  12345",
+		Sprint(fakeNumber{}, UpTo(15), DigitsPerRow(10), CountThreshold(100)))
+}
+
+func TestPrintMissingDigits(t *testing.T) {
+	var pb PositionsBuilder
+	actual := Sprint(
+		newFakeNumber(),
+		pb.Add(45).Add(48).AddRange(50, 52).Build(),
+		DigitsPerRow(6),
+		DigitsPerColumn(3),
+		MissingDigits("--"))
+	expected := `42  ------  6----
+48   9-- 1  2`
+	assert.Equal(t, expected, actual)
+	assert.Equal(
+		t,
+		"0.-2--5 -78",
+		Sprint(
+			newFakeNumber(),
+			pb.Add(1).Add(4).AddRange(6, 8).Build(),
+			MissingDigits("--"),
+			MissingDigit('-')))
+	assert.Equal(
+		t,
+		"0.?? 2 3",
+		Sprint(fakeNumber{}, Between(1, 3), MissingDigits("??")))
+	assert.Equal(
+		t,
+		len(Sprint(newFakeNumber(), UpTo(120), MissingDigits("??"))),
+		EstimateSize(120, MissingDigits("??")))
+}
+
+func TestPrintStrict(t *testing.T) {
+	var pb PositionsBuilder
+	var sb strings.Builder
+	_, err := Fprint(
+		&sb,
+		newFakeNumber(),
+		pb.AddRange(0, 7).AddRange(9, 12).Build(),
+		Strict(true),
+		DigitsPerRow(10))
+	assert.ErrorIs(t, err, ErrMissingDigit)
+	assert.ErrorContains(t, err, "position 7")
+	assert.Equal(t, "  0.12345 67", sb.String())
+	sb.Reset()
+	_, err = Fprint(
+		&sb,
+		newFakeNumber(),
+		pb.AddRange(0, 7).AddRange(9, 12).Build(),
+		Strict(true),
+		GapFill(GapSkip),
+		ShowCount(false))
+	assert.NoError(t, err)
+	assert.Equal(t, "0.12345 67012", sb.String())
+	assert.Equal(
+		t,
+		"0.12345 67890 12\n",
+		Swrite(newFakeNumberRange(0, 12), Strict(true), LeadingDecimal(true)))
+}
+
+func TestPrintRowsPerPage(t *testing.T) {
+	actual := Sprint(
+		fakeNumber{},
+		UpTo(25),
+		DigitsPerRow(5),
+		RowsPerPage(2),
+		Ruler(true),
+		RulerEachPage(true))
+	expected := "    0\n  0.12345\n 5  67890\n" +
+		"\f    0\n10  12345\n15  67890\n" +
+		"\f    0\n20  12345"
+	assert.Equal(t, expected, actual)
+	assert.Equal(
+		t,
+		"0.12345\n  67890\n--\n  12345\n",
+		Swrite(
+			newFakeNumberRange(0, 15),
+			DigitsPerRow(5),
+			ShowCount(false),
+			LeadingDecimal(true),
+			RowsPerPage(2),
+			PageSeparator("--\n")))
+	assert.Equal(
+		t,
+		len(actual),
+		EstimateSize(
+			25,
+			DigitsPerRow(5),
+			RowsPerPage(2),
+			Ruler(true),
+			RulerEachPage(true)))
+}
+
+func TestPrintColumnHeaders(t *testing.T) {
+	actual := Sprint(
+		fakeNumber{},
+		UpTo(24),
+		DigitsPerRow(12),
+		DigitsPerColumn(4),
+		Ruler(true),
+		ColumnHeaders(true))
+	expected := `    0    4    8
+    0123 0123 0123
+  0.1234 5678 9012
+12  3456 7890 1234`
+	assert.Equal(t, expected, actual)
+	assert.Equal(
+		t,
+		" 0 1 2\n-- 2 3\n",
+		Swrite(
+			newFakeNumberRange(1, 3),
+			DigitsPerRow(3),
+			DigitsPerColumn(0),
+			ShowCount(false),
+			ColumnHeaders(true),
+			MissingDigits("--")))
+	assert.Equal(
+		t,
+		len(actual),
+		EstimateSize(
+			24,
+			DigitsPerRow(12),
+			DigitsPerColumn(4),
+			Ruler(true),
+			ColumnHeaders(true)))
+}
+
+func TestPrintAlignRight(t *testing.T) {
+	actual := Sprint(
+		fakeNumber{},
+		UpTo(17),
+		DigitsPerRow(7),
+		DigitsPerColumn(3),
+		Align(AlignRight),
+		Ruler(true))
+	expected := `    0 1   4
+  0.1 234 567
+ 7  8 901 234
+14        567`
+	assert.Equal(t, expected, actual)
+	assert.Equal(
+		t,
+		len(actual),
+		EstimateSize(
+			17,
+			DigitsPerRow(7),
+			DigitsPerColumn(3),
+			Align(AlignRight),
+			Ruler(true)))
+	assert.Equal(
+		t,
+		"1,234,567\n",
+		Swrite(
+			newFakeNumberRange(0, 7),
+			DigitsPerRow(0),
+			DigitsPerColumn(3),
+			ShowCount(false),
+			ColumnSeparator(","),
+			Align(AlignRight)))
+	assert.Equal(
+		t,
+		"0.12345 67890\n        12345\n",
+		Swrite(
+			newFakeNumberRange(0, 15),
+			DigitsPerRow(10),
+			ShowCount(false),
+			LeadingDecimal(true),
+			Align(AlignRight),
+			ColumnOffset(0)))
+}
+
+func TestPrintOnProgress(t *testing.T) {
+	type progress struct{ done, total int }
+	var actual []progress
+	onProgress := OnProgress(4, func(done, total int) {
+		actual = append(actual, progress{done, total})
+	})
+	var pb PositionsBuilder
+	Sprint(fakeNumber{}, pb.AddRange(0, 6).AddRange(10, 13).Build(), onProgress)
+	assert.Equal(t, []progress{{4, 13}, {12, 13}, {13, 13}}, actual)
+	actual = nil
+	Swrite(newFakeNumberRange(0, 9), Reverse(true), onProgress)
+	assert.Equal(t, []progress{{4, 9}, {8, 9}, {9, 9}}, actual)
+	actual = nil
+	Fprint(&maxBytesWriter{maxBytes: 3}, fakeNumber{}, UpTo(5), onProgress)
+	assert.Equal(t, []progress{{4, 5}}, actual)
+}
+
+func TestPrintMissingDigitFunc(t *testing.T) {
+	var pb PositionsBuilder
+	missingDigitFunc := MissingDigitFunc(func(posit int) rune {
+		if posit < 5 {
+			return '?'
+		}
+		return '_'
+	})
+	p := pb.AddRange(2, 4).AddRange(7, 9).Build()
+	assert.Equal(
+		t,
+		"0.??34? __89",
+		Sprint(fakeNumber{}, p, MissingDigit('-'), missingDigitFunc))
+	assert.Equal(
+		t,
+		"0.--34- --89",
+		Sprint(fakeNumber{}, p, missingDigitFunc, MissingDigitFunc(nil),
+			MissingDigit('-')))
+	assert.Equal(
+		t,
+		"0.00340 0089",
+		Sprint(fakeNumber{}, p, missingDigitFunc, GapFill(GapZero)))
+}
+
+func TestPrintMissingDigitFuncGapAcrossRows(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(0, 2).AddRange(35, 37).Build()
+	var posits []int
+	missingDigitFunc := MissingDigitFunc(func(posit int) rune {
+		posits = append(posits, posit)
+		return rune('a' + posit%26)
+	})
+	assert.Equal(
+		t,
+		"  0.12cde fghij\n30  efghi 67",
+		Sprint(fakeNumber{}, p, DigitsPerRow(10), missingDigitFunc))
+	assert.Equal(
+		t,
+		[]int{2, 3, 4, 5, 6, 7, 8, 9, 30, 31, 32, 33, 34},
+		posits)
+	assert.Equal(
+		t,
+		" 1  2cdef ghij\n31  fghi6 7",
+		Sprint(
+			fakeNumber{}, p, DigitsPerRow(10), ColumnOffset(1),
+			missingDigitFunc))
+}
+
+func TestSprintE(t *testing.T) {
+	actual, n, err := SprintE(fakeNumber{}, UpTo(7), DigitsPerRow(5))
+	assert.NoError(t, err)
+	assert.Equal(t, " 0.12345\n5  67", actual)
+	assert.Equal(t, len(actual), n)
+	var pb PositionsBuilder
+	actual, n, err = SprintE(
+		fakeNumber{}, pb.Add(0).Add(2).Build(), Strict(true))
+	assert.ErrorIs(t, err, ErrMissingDigit)
+	assert.Equal(t, "0.1", actual)
+	assert.Equal(t, len(actual), n)
+	_, _, err = SprintE(fakeNumber{}, UpTo(7), Radix(50))
+	assert.Error(t, err)
+}
+
+func TestPrintWordsPerGroup(t *testing.T) {
+	options := []Option{
+		DigitsPerRow(16),
+		WordSize(4),
+		WordsPerGroup(2),
+		ShowCount(false),
+		LeadingDecimal(false),
+		Ruler(true),
+	}
+	actual := Sprint(fakeNumber{}, UpTo(22), options...)
+	expected := `0    4     8    12
+1234 5678  9012 3456
+7890 12`
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(22, options...))
+	assert.Equal(
+		t,
+		"12|345/678|901|234\n",
+		Swrite(
+			newFakeNumberRange(0, 14),
+			DigitsPerRow(0),
+			ShowCount(false),
+			WordSize(3),
+			ColumnSeparator("|"),
+			WordsPerGroup(3),
+			GroupSeparator("/"),
+			Align(AlignRight)))
+	actual = Swrite(
+		newFakeNumberRange(0, 22),
+		DigitsPerRow(16),
+		WordSize(4),
+		WordsPerGroup(2),
+		FitWidth(30))
+	assert.Equal(t, " 0  1234 5678  9012 3456  7890\n20  12\n", actual)
+}
+
+func TestPrintVerbose(t *testing.T) {
+	var pb PositionsBuilder
+	actual := Sprint(
+		fakeNumber{},
+		pb.AddRange(0, 3).AddRange(4, 6).Add(9).Build(),
+		DigitsPerRow(4),
+		Verbose(true),
+		Ruler(true))
+	expected := `0  0:1 1:2 2:3 3:.
+4  4:5 5:6 6:. 7:.
+8  8:. 9:0`
+	assert.Equal(t, expected, actual)
+	pb = PositionsBuilder{}
+	actual = Sprint(
+		fakeNumber{},
+		pb.AddRange(0, 2).Add(35).Build(),
+		DigitsPerRow(10),
+		Verbose(true))
+	expected = ` 0  0:1 1:2 2:. 3:. 4:. 5:. 6:. 7:. 8:. 9:.
+30  30:. 31:. 32:. 33:. 34:. 35:6`
+	assert.Equal(t, expected, actual)
+	assert.Equal(
+		t,
+		"0:? 1:2 \x1b[1m2:3\x1b[0m\n",
+		Swrite(
+			newFakeNumberRange(1, 3),
+			Verbose(true),
+			ShowCount(false),
+			MissingDigits("?"),
+			Highlight(map[int]string{2: "1"})))
+}
+
+func TestPrintColumnWidths(t *testing.T) {
+	options := []Option{
+		DigitsPerRow(12),
+		ColumnWidths([]int{1, 0, 5}),
+		LeadingDecimal(false),
+		Ruler(true),
+	}
+	actual := Sprint(fakeNumber{}, UpTo(15), options...)
+	expected := `    0 1     6     11
+ 0  1 23456 78901 2
+12  3 45`
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(15, options...))
+	assert.Equal(
+		t,
+		"0  12345 67890\n",
+		Swrite(
+			newFakeNumberRange(0, 10),
+			ColumnWidths([]int{1, 5}),
+			DigitsPerColumn(5)))
+	assert.Equal(
+		t,
+		"0   12 34567 8\n",
+		Swrite(
+			newFakeNumberRange(0, 8),
+			DigitsPerRow(9),
+			ColumnWidths([]int{1, 5}),
+			Align(AlignRight)))
+	assert.Equal(
+		t,
+		"0  1234567890\n",
+		Swrite(newFakeNumberRange(0, 10), ColumnWidths(nil)))
+}
+
+func TestPrintPadLastRow(t *testing.T) {
+	options := []Option{DigitsPerRow(8), DigitsPerColumn(3), PadLastRow(true)}
+	actual := Sprint(fakeNumber{}, UpTo(10), options...)
+	expected := " 0.123 456 78\n8  90. ... ..|"
+	assert.Equal(t, expected, actual+"|")
+	assert.Equal(t, len(actual), EstimateSize(10, options...))
+	options = append(options, PadWithSpaces(true), MissingDigits("--"))
+	actual = Swrite(newFakeNumberRange(0, 10), options...)
+	expected = "0   1 2 3  4 5 6  7 8\n" +
+		"8   9 0              \n"
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(10, append(
+		options, LeadingDecimal(false), TrailingLF(true))...))
+	assert.Equal(
+		t,
+		"0  123 45\n",
+		Swrite(newFakeNumberRange(0, 5), DigitsPerRow(0), DigitsPerColumn(3),
+			PadLastRow(true)))
+}
+
+func TestPrintLimit(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 5).AddRange(10, 1000)
+	p := pb.Build()
+	actual := Sprint(
+		fakeNumber{}, p, DigitsPerRow(10), Limit(7), TruncationMarker("..."))
+	expected := "   0.12345 .....\n 10  12..."
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		fakeNumber{}, UpTo(7), DigitsPerRow(10), Limit(7),
+		TruncationMarker("..."))
+	assert.Equal(t, "0.12345 67...", actual)
+	actual = Sprint(
+		newFakeNumberRange(0, 7), UpTo(20), DigitsPerRow(10), Limit(7),
+		TruncationMarker("..."))
+	assert.Equal(t, "  0.12345 67", actual)
+	assert.Equal(
+		t, "0  12345\n", Swrite(newFakeNumberRange(0, 10), Limit(5)))
+}
+
+func TestPrintMaxRows(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(0, 5).AddRange(30, 1000).Build()
+	actual := Sprint(
+		fakeNumber{}, p, DigitsPerRow(10), MaxRows(3), TruncationMarker("..."))
+	expected := "   0.12345 .....\n 30  12345 67890\n 40  12345 67890..."
+	assert.Equal(t, expected, actual)
+	actual = Sprint(
+		newFakeNumberRange(0, 20), UpTo(20), DigitsPerRow(10), MaxRows(2),
+		TruncationMarker("..."))
+	assert.Equal(t, "  0.12345 67890\n10  12345 67890", actual)
+	actual = Sprint(
+		newFakeNumberRange(0, 21), UpTo(21), DigitsPerRow(10), MaxRows(2),
+		TruncationMarker("..."))
+	assert.Equal(t, "  0.12345 67890\n10  12345 67890...", actual)
+	assert.Equal(
+		t,
+		" 0  12345\n 5  67890\n",
+		Swrite(newFakeNumberRange(0, 100), DigitsPerRow(5), MaxRows(2)))
+	var count int
+	Swrite(
+		newFakeNumberRange(0, 100), DigitsPerRow(5), MaxRows(2),
+		OnDigit(func(posit, digit int) { count++ }))
+	assert.LessOrEqual(t, count, 11)
+	assert.Equal(
+		t,
+		"0.12345 67",
+		Sprint(fakeNumber{}, UpTo(7), DigitsPerRow(0), MaxRows(1)))
+}
+
+func TestPrintMaxRowsGaps(t *testing.T) {
+	var pb PositionsBuilder
+	p := pb.AddRange(0, 5).AddRange(12, 15).Build()
+	assert.Equal(
+		t,
+		"  0.12345 .....",
+		Sprint(fakeNumber{}, p, DigitsPerRow(10), MaxRows(1)))
+	assert.Equal(
+		t,
+		"  0.12345 .....\n10  ..345",
+		Sprint(fakeNumber{}, p, DigitsPerRow(10), MaxRows(2)))
+	p = pb.AddRange(0, 5).AddRange(40, 45).Build()
+	assert.Equal(
+		t,
+		"  0.12345 ........",
+		Sprint(
+			newFakeNumberRange(0, 45), p, DigitsPerRow(10), MaxRows(1),
+			TruncationMarker("...")))
+	assert.Equal(
+		t,
+		"  0.12345 00000...",
+		Sprint(
+			newFakeNumberRange(0, 45), p, DigitsPerRow(10), MaxRows(1),
+			GapFill(GapZero), TruncationMarker("...")))
+}
+
+func TestPrintTabColumns(t *testing.T) {
+	actual := Sprint(
+		fakeNumber{}, UpTo(12), DigitsPerRow(6), DigitsPerColumn(3),
+		TabColumns(true))
+	assert.Equal(t, "123\t456\n789\t012", actual)
+	options := []Option{
+		DigitsPerRow(6), DigitsPerColumn(3), WordsPerGroup(1),
+		TabColumns(true), ShowCount(true)}
+	actual = Swrite(newFakeNumberRange(0, 8), options...)
+	assert.Equal(t, "0\t123\t456\n6\t78\n", actual)
+	assert.Equal(t, len(actual), EstimateSize(8, append(
+		options, LeadingDecimal(false), TrailingLF(true))...))
+}
+
+func TestPrintIntegerWidth(t *testing.T) {
+	options := []Option{
+		ShowCount(false), DigitsPerRow(0), IntegerWidth(7)}
+	first := Sprint(
+		newFakeNumber(), UpTo(8), append(options, LeadingInteger(1))...)
+	second := Sprint(
+		newFakeNumber(), UpTo(8), append(options, LeadingInteger(6))...)
+	assert.Equal(t, "       1.2345 678", first)
+	assert.Equal(t, " 12345 6.78", second)
+	assert.Equal(t, strings.Index(first, "."), strings.Index(second, "."))
+	options = []Option{
+		DigitsPerRow(10), LeadingInteger(3), IntegerWidth(4), Ruler(true)}
+	actual := Sprint(newFakeNumber(), UpTo(15), options...)
+	expected := `     0     5
+ 0   123.45 67890
+10   12345`
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(15, options...))
+	assert.Equal(
+		t,
+		"1.2345",
+		Sprint(
+			newFakeNumber(), UpTo(5), ShowCount(false), LeadingInteger(1),
+			IntegerWidth(1)))
+}
+
+func TestPrintDecimalColumn(t *testing.T) {
+	options := []Option{ShowCount(false), DigitsPerRow(0), DecimalColumn(6)}
+	first := Sprint(
+		newFakeNumber(), UpTo(8), append(options, LeadingInteger(1))...)
+	second := Sprint(
+		newFakeNumber(), UpTo(8), append(options, LeadingInteger(4))...)
+	assert.Equal(t, "     1.2345 678", first)
+	assert.Equal(t, "  1234.5 678", second)
+	assert.Equal(t, 6, strings.Index(first, "."))
+	assert.Equal(t, 6, strings.Index(second, "."))
+	options = []Option{
+		DigitsPerRow(10), LeadingInteger(3), DecimalColumn(8),
+		IntegerWidth(20)}
+	actual := Sprint(newFakeNumber(), UpTo(15), options...)
+	assert.Equal(t, " 0   123.45 67890\n10   12345", actual)
+	assert.Equal(t, 8, strings.Index(actual, "."))
+	assert.Equal(t, len(actual), EstimateSize(15, options...))
+	var sb strings.Builder
+	_, err := Fprint(
+		&sb, newFakeNumber(), UpTo(8), ShowCount(false), LeadingInteger(4),
+		DecimalColumn(3))
+	assert.Error(t, err)
+	assert.Empty(t, sb.String())
+}
+
+func TestPrintRepeat(t *testing.T) {
+	digits, err := FromString("16666")
+	assert.NoError(t, err)
+	prelude, period, ok := DetectRepeat(digits, UpTo(5))
+	assert.True(t, ok)
+	options := []Option{
+		ShowCount(false), DigitsPerColumn(2), LeadingInteger(1),
+		Repeat(prelude, prelude+period)}
+	actual := Sprint(digits, UpTo(3), options...)
+	assert.Equal(t, "1.6̅ 6", actual)
+	assert.Equal(t, len(actual), EstimateSize(3, options...))
+	actual = Sprint(
+		digits, UpTo(3), ShowCount(false), Repeat(1, 3),
+		Highlight(map[int]string{2: "1"}))
+	assert.Equal(t, "0.16̅\x1b[1m6̅\x1b[0m", actual)
+}
+
+func TestPrintRowPrefixAndSuffix(t *testing.T) {
+	options := []Option{
+		DigitsPerRow(10), RowPrefix("// "), RowSuffix(" ·"),
+		RowSeparator("\n"), Ruler(true), LeadingDecimal(false)}
+	actual := Sprint(newFakeNumber(), UpTo(25), options...)
+	expected := `       0     5
+ 0  // 12345 67890 ·
+
+10  // 12345 67890 ·
+
+20  // 12345 ·`
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(25, options...))
+	var builder strings.Builder
+	_, err := FprintParallel(
+		&builder, newFakeNumber(), UpTo(25), 3, options...)
+	assert.NoError(t, err)
+	assert.Equal(t, actual, builder.String())
+}
+
+func TestPrintMaxLineBytes(t *testing.T) {
+	chinese := CustomDigits([10]rune{
+		'零', '一', '二', '三', '四', '五', '六', '七', '八', '九'})
+	options := []Option{
+		chinese, DigitsPerRow(10), MaxLineBytes(30), ShowCount(false),
+		LeadingDecimal(false)}
+	actual := Sprint(newFakeNumber(), UpTo(20), options...)
+	lines := strings.Split(actual, "\n")
+	assert.Len(t, lines, 3)
+	for _, line := range lines {
+		assert.LessOrEqual(t, len(line), 30)
+	}
+	assert.Equal(t, "一二三四五 六七八九", lines[0])
+	actual = Swrite(newFakeNumberRange(0, 20), MaxLineBytes(12))
+	assert.Equal(t, " 0  12345 67\n 7  89012 34\n14  56789 0\n", actual)
+	assert.Equal(
+		t,
+		"12345 67890\n",
+		Swrite(
+			newFakeNumberRange(0, 10), DigitsPerRow(0), ShowCount(false),
+			MaxLineBytes(11)))
+	_, err := Fprint(
+		io.Discard, newFakeNumber(), UpTo(20), chinese, MaxLineBytes(2))
+	assert.ErrorIs(t, err, ErrLineTooLong)
+}
+
+func TestPrintSign(t *testing.T) {
+	options := []Option{DigitsPerRow(10), Sign(true)}
+	actual := Sprint(newFakeNumber(), UpTo(15), options...)
+	expected := `  -0.12345 67890
+10   12345`
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(15, options...))
+	actual = Sprint(
+		newFakeNumber(), UpTo(15), DigitsPerRow(10), Sign(true),
+		LeadingInteger(1))
+	expected = ` 0  -1.2345 67890
+10   12345`
+	assert.Equal(t, expected, actual)
+	assert.Equal(
+		t,
+		"-0.12345",
+		Sprint(newFakeNumber(), UpTo(5), Sign(true), ShowCount(false)))
+	assert.Equal(
+		t,
+		"-12345\n",
+		Swrite(newFakeNumberRange(0, 5), Sign(true), ShowCount(false)))
+}
+
+func TestPrintIndent(t *testing.T) {
+	options := []Option{
+		DigitsPerRow(10), RowSeparator("\n"), Ruler(true), Indent(2)}
+	actual := Sprint(newFakeNumber(), UpTo(25), options...)
+	expected := `      0     5
+    0.12345 67890
+  
+  10  12345 67890
+  
+  20  12345`
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(25, options...))
+	var builder strings.Builder
+	_, err := FprintParallel(
+		&builder, newFakeNumber(), UpTo(25), 3, options...)
+	assert.NoError(t, err)
+	assert.Equal(t, actual, builder.String())
+	options = []Option{
+		DigitsPerRow(5), Indent(1), MarkdownFence(""), TrailingLF(true)}
+	actual = Swrite(newFakeNumberRange(0, 7), options...)
+	assert.Equal(t, " ```\n 0  12345\n 5  67\n ```\n", actual)
+	options = append(options, CountFormat(func(n int) string {
+		return "`" + strconv.Itoa(n) + "`"
+	}))
+	actual = Swrite(newFakeNumberRange(0, 7), options...)
+	assert.Equal(t, " ```\n `0`  12345\n `5`  67\n ```\n", actual)
+}
+
+func TestPrintOnDigit(t *testing.T) {
+	var posits, digits []int
+	onDigit := OnDigit(func(posit, digit int) {
+		posits = append(posits, posit)
+		digits = append(digits, digit)
+	})
+	var pb PositionsBuilder
+	pb.AddRange(0, 3).AddRange(6, 8)
+	actual := Sprint(newFakeNumber(), pb.Build(), onDigit, Limit(4))
+	assert.Equal(t, "0.123.. .7", actual)
+	assert.Equal(t, []int{0, 1, 2, 6}, posits)
+	assert.Equal(t, []int{1, 2, 3, 7}, digits)
+	posits, digits = nil, nil
+	Swrite(newFakeNumberRange(0, 3), onDigit, Reverse(true))
+	assert.Equal(t, []int{2, 1, 0}, posits)
+	assert.Equal(t, []int{3, 2, 1}, digits)
+}
+
+func TestPrintCountRadix(t *testing.T) {
+	options := []Option{DigitsPerRow(16), DigitsPerColumn(4), CountRadix(16)}
+	actual := Swrite(newFakeNumberRange(0, 300), options...)
+	lines := strings.Split(actual, "\n")
+	assert.Equal(t, " 10  7890 1234 5678 9012", lines[1])
+	assert.Equal(t, "120  9012 3456 7890", lines[18])
+	assert.Equal(t, len(actual), EstimateSize(300, append(
+		options, LeadingDecimal(false), TrailingLF(true))...))
+	assert.Equal(
+		t,
+		Swrite(newFakeNumberRange(0, 300), DigitsPerRow(16)),
+		Swrite(newFakeNumberRange(0, 300), DigitsPerRow(16), CountRadix(1)))
+}
+
+func TestPrintCountOffset(t *testing.T) {
+	options := []Option{DigitsPerRow(10), CountOffset(95)}
+	actual := Swrite(newFakeNumberRange(0, 25), options...)
+	expected := " 95  12345 67890\n105  12345 67890\n115  12345\n"
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(25, append(
+		options, LeadingDecimal(false), TrailingLF(true))...))
+	assert.Equal(
+		t,
+		"  0.12345 67890\n30  12345",
+		Sprint(
+			fakeNumber{}, UpTo(15), DigitsPerRow(10), CountOffset(20),
+			CountFormat(func(count int) string {
+				return strconv.Itoa(count)
+			})))
+	assert.Equal(
+		t,
+		"-5  12345\n 0  67890\n",
+		Swrite(
+			newFakeNumberRange(0, 10), DigitsPerRow(5), CountOffset(-5)))
+}
+
+func TestPrintBinaryGrouping(t *testing.T) {
+	bits, err := FromString("0110100111000011101")
+	assert.NoError(t, err)
+	options := []Option{
+		Radix(2), BinaryGrouping(true), ColumnSeparator("_"),
+		GroupSeparator(" "), DigitsPerRow(20)}
+	actual := Swrite(bits, options...)
+	expected := " 0  0110_1001 1100_0011\n16  101\n"
+	assert.Equal(t, expected, actual)
+	assert.Equal(t, len(actual), EstimateSize(19, append(
+		options, LeadingDecimal(false), TrailingLF(true))...))
+	assert.Equal(
+		t,
+		"0  0110 1001  1100 0011  101\n",
+		Swrite(bits, Radix(2), BinaryGrouping(true), DigitsPerRow(0)))
+	assert.Equal(
+		t,
+		" 0  0110\n 4  1001\n 8  1100\n12  0011\n16  101\n",
+		Swrite(bits, Radix(2), BinaryGrouping(true), DigitsPerRow(4)))
+}
+
+func TestPrintSkipFirstCount(t *testing.T) {
+	options := []Option{DigitsPerRow(10), SkipFirstCount(true)}
+	actual := Swrite(newFakeNumberRange(0, 25), options...)
+	assert.Equal(t, "    12345 67890\n10  12345 67890\n20  12345\n", actual)
+	actual = Sprint(newFakeNumber(), Between(12, 25), options...)
+	assert.Equal(t, "    ..345 67890\n20  12345", actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 5), DigitsPerRow(0), SkipFirstCount(true))
+	assert.Equal(t, "   12345\n", actual)
+}
@@ -0,0 +1,136 @@
+package numprint
+
+import (
+	"bytes"
+	"io"
+	"iter"
+)
+
+const (
+	readerChunkSize = 1024
+)
+
+// Reader reads the formatted digits of a Writable. Reader formats the
+// digits lazily as they are read so that the formatted text is never held
+// in memory all at once.
+type Reader struct {
+	buffer  bytes.Buffer
+	printer *printer
+	next    func() (int, int, bool)
+	stop    func()
+	done    bool
+}
+
+// NewReader returns a Reader that reads what Fwrite would write for s and
+// options. If the caller does not read the returned Reader until io.EOF, the
+// caller must call Close to release resources.
+func NewReader(s Writable, options ...Option) *Reader {
+	settings := writeSettings(options)
+	result := &Reader{}
+	result.printer = newPrinter(&result.buffer, endOf(s), settings)
+	if settings.reverse {
+		result.next, result.stop = iter.Pull2(s.Backward())
+	} else {
+		result.next, result.stop = iter.Pull2(s.All())
+	}
+	return result
+}
+
+// Read reads up to len(p) bytes of the formatted digits into p. Read
+// returns io.EOF after the last byte including any trailing line feed.
+func (r *Reader) Read(p []byte) (n int, err error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+	for r.buffer.Len() < len(p) && !r.done {
+		r.fill()
+	}
+	if r.buffer.Len() == 0 {
+		if err := r.printer.Err(); err != nil {
+			return 0, err
+		}
+		return 0, io.EOF
+	}
+	return r.buffer.Read(p)
+}
+
+// Close releases the resources of this Reader. After Close, Read returns
+// io.EOF. Close always returns nil.
+func (r *Reader) Close() error {
+	r.stop()
+	r.buffer.Reset()
+	r.done = true
+	return nil
+}
+
+func (r *Reader) fill() {
+	for i := 0; i < readerChunkSize; i++ {
+		posit, digit, ok := r.next()
+		if !ok {
+			r.finish()
+			return
+		}
+		r.printer.Consume(posit, digit)
+		if !r.printer.CanConsume() {
+			r.finish()
+			return
+		}
+	}
+	r.printer.flush()
+}
+
+func (r *Reader) finish() {
+	r.stop()
+	r.printer.Finish()
+	r.done = true
+}
+
+// RawReader returns a reader that reads just the digits of s from
+// beginning to end as the bytes '0' through '9' with no margins,
+// separators, line feeds, or missing digits. RawReader skips layout
+// entirely, so it is the fastest way to feed the digits of s to a hash or
+// to a network connection. If s has a digit greater than 9, reading fails
+// with an error at that digit. If the caller does not read the returned
+// reader until io.EOF, the caller must call Close to release resources.
+func RawReader(s Writable) io.ReadCloser {
+	result := &rawReader{digits: digitSet{chars: digitChars, radix: 10}}
+	result.next, result.stop = iter.Pull2(s.All())
+	return result
+}
+
+type rawReader struct {
+	digits digitSet
+	next   func() (int, int, bool)
+	stop   func()
+	err    error
+}
+
+func (r *rawReader) Read(p []byte) (n int, err error) {
+	for n < len(p) && r.err == nil {
+		posit, digit, ok := r.next()
+		if !ok {
+			r.Close()
+			break
+		}
+		if r.err = r.digits.Check(posit, digit); r.err != nil {
+			r.stop()
+			break
+		}
+		p[n] = r.digits.chars[digit]
+		n++
+	}
+	if n > 0 || len(p) == 0 {
+		return n, nil
+	}
+	return 0, r.err
+}
+
+// Close releases the resources of this reader. After Close, Read returns
+// io.EOF. Close always returns nil.
+func (r *rawReader) Close() error {
+	r.stop()
+	if r.err == nil {
+		r.err = io.EOF
+	}
+	return nil
+}
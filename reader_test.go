@@ -0,0 +1,88 @@
+package numprint
+
+import (
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReader(t *testing.T) {
+	number := newFakeNumberRange(0, 10000)
+	expected := Swrite(number)
+	actual, err := io.ReadAll(NewReader(number))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(actual))
+	actual, err = io.ReadAll(iotest.OneByteReader(NewReader(number)))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(actual))
+	assert.NoError(t, iotest.TestReader(NewReader(number), []byte(expected)))
+}
+
+func TestReaderOptions(t *testing.T) {
+	number := newFakeNumberRange(3, 55)
+	options := []Option{DigitsPerRow(20), Reverse(true), TrailingLF(false)}
+	expected := Swrite(number, options...)
+	actual, err := io.ReadAll(NewReader(number, options...))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(actual))
+}
+
+func TestReaderEmpty(t *testing.T) {
+	actual, err := io.ReadAll(NewReader(newFakeNumberRange(0, 0)))
+	assert.NoError(t, err)
+	assert.Equal(t, "\n", string(actual))
+}
+
+func TestReaderError(t *testing.T) {
+	r := NewReader(fakeDigits{1, 2, 12, 3}, Radix(10))
+	actual, err := io.ReadAll(r)
+	assert.ErrorContains(t, err, "digit 12 at position 2")
+	assert.Equal(t, "0  12", string(actual))
+}
+
+func TestReaderClose(t *testing.T) {
+	r := NewReader(newFakeNumberRange(0, 10000))
+	buffer := make([]byte, 10)
+	n, err := r.Read(buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "   0  1234", string(buffer[:n]))
+	assert.NoError(t, r.Close())
+	n, err = r.Read(buffer)
+	assert.Equal(t, io.EOF, err)
+	assert.Zero(t, n)
+}
+
+func TestRawReader(t *testing.T) {
+	number := newFakeNumberRange(0, 10000)
+	var expected []byte
+	for _, digit := range number.All() {
+		expected = append(expected, byte('0'+digit))
+	}
+	actual, err := io.ReadAll(RawReader(number))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, actual)
+	assert.NoError(t, iotest.TestReader(RawReader(number), expected))
+	actual, err = io.ReadAll(RawReader(newFakeNumberRange(3, 8)))
+	assert.NoError(t, err)
+	assert.Equal(t, "45678", string(actual))
+}
+
+func TestRawReaderError(t *testing.T) {
+	actual, err := io.ReadAll(RawReader(fakeDigits{1, 2, 12, 3}))
+	assert.ErrorContains(t, err, "digit 12 at position 2")
+	assert.Equal(t, "12", string(actual))
+}
+
+func TestRawReaderClose(t *testing.T) {
+	r := RawReader(newFakeNumberRange(0, 10000))
+	buffer := make([]byte, 4)
+	n, err := r.Read(buffer)
+	assert.NoError(t, err)
+	assert.Equal(t, "1234", string(buffer[:n]))
+	assert.NoError(t, r.Close())
+	n, err = r.Read(buffer)
+	assert.Zero(t, n)
+	assert.Equal(t, io.EOF, err)
+}
@@ -0,0 +1,48 @@
+package numprint
+
+// DetectRepeat looks for the digits of s at positions p to end in a block
+// of digits that repeats. preludeLen is the number of digits before the
+// repeating part starts, and periodLen is the number of digits in the
+// repeating block. DetectRepeat picks the shortest block that repeats at
+// least twice to the end and then the shortest prelude for that block.
+// DetectRepeat counts digits in the order of the positions in p, so
+// preludeLen is relative to the first position in p. ok is false if no
+// block repeats or if s is missing a digit at any position in p.
+func DetectRepeat(s Printable, p Positions) (
+	preludeLen, periodLen int, ok bool) {
+	digits, ok := digitsAt(s, p)
+	if !ok {
+		return 0, 0, false
+	}
+	n := len(digits)
+	for period := 1; 2*period <= n; period++ {
+		prelude := n - period
+		for prelude > 0 && digits[prelude-1] == digits[prelude-1+period] {
+			prelude--
+		}
+		if n-prelude >= 2*period {
+			return prelude, period, true
+		}
+	}
+	return 0, 0, false
+}
+
+// digitsAt returns the digits of s at positions p in order. digitsAt
+// returns false if s is missing a digit at any position in p.
+func digitsAt(s Printable, p Positions) ([]int, bool) {
+	result := make([]int, 0, p.Count())
+	for pr := range p.All() {
+		next := pr.Start
+		for posit, digit := range s.AllInRange(pr.Start, pr.End) {
+			if posit != next {
+				return nil, false
+			}
+			result = append(result, digit)
+			next++
+		}
+		if next != pr.End {
+			return nil, false
+		}
+	}
+	return result, true
+}
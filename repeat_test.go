@@ -0,0 +1,49 @@
+package numprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectRepeat(t *testing.T) {
+	testCases := []struct {
+		digits  string
+		prelude int
+		period  int
+		ok      bool
+	}{
+		{digits: "142857142857", prelude: 0, period: 6, ok: true},
+		{digits: "1666666", prelude: 1, period: 1, ok: true},
+		{digits: "0833333", prelude: 2, period: 1, ok: true},
+		{digits: "12345454545", prelude: 3, period: 2, ok: true},
+		{digits: "1234567", ok: false},
+		{digits: "7", ok: false},
+		{digits: "", ok: false},
+	}
+	for _, tc := range testCases {
+		digits, err := FromString(tc.digits)
+		assert.NoError(t, err)
+		prelude, period, ok := DetectRepeat(digits, UpTo(digits.Len()))
+		assert.Equal(t, tc.ok, ok, tc.digits)
+		assert.Equal(t, tc.prelude, prelude, tc.digits)
+		assert.Equal(t, tc.period, period, tc.digits)
+	}
+}
+
+func TestDetectRepeatPositions(t *testing.T) {
+	digits, err := FromString("90121212")
+	assert.NoError(t, err)
+	prelude, period, ok := DetectRepeat(digits, Between(1, 8))
+	assert.True(t, ok)
+	assert.Equal(t, 1, prelude)
+	assert.Equal(t, 2, period)
+	_, _, ok = DetectRepeat(digits, UpTo(9))
+	assert.False(t, ok)
+	var pb PositionsBuilder
+	pb.AddRange(2, 4).AddRange(6, 8)
+	prelude, period, ok = DetectRepeat(digits, pb.Build())
+	assert.True(t, ok)
+	assert.Equal(t, 0, prelude)
+	assert.Equal(t, 2, period)
+}
@@ -0,0 +1,38 @@
+package numprint
+
+import (
+	"context"
+	"errors"
+	"io"
+	"iter"
+	"slices"
+)
+
+var errStopRows = errors.New("numprint: stop rows")
+
+// Rows works like Sprint except that it returns each row as a separate
+// string. Each row includes its left margin and column separators but no
+// line feed. The row separator and any line that Ruler prints are not
+// part of any row. Rows ignores the OnRow option.
+func Rows(s Printable, p Positions, options ...Option) []string {
+	return slices.Collect(RowsSeq(s, p, options...))
+}
+
+// RowsSeq works like Rows except that it returns the rows lazily. RowsSeq
+// formats each row only when it is needed, so stopping early saves the
+// work of formatting the remaining rows.
+func RowsSeq(s Printable, p Positions, options ...Option) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		settings := printSettings(options)
+		settings.reverse = false
+		var printer *printer
+		settings.onRow = func(rowIndex int, text string) {
+			if !yield(text) {
+				printer.Abort(errStopRows)
+			}
+		}
+		printer = newPrinter(io.Discard, p.End(), settings)
+		fromSequenceWithPositions(context.Background(), s, p, printer)
+		printer.Finish()
+	}
+}
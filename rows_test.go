@@ -0,0 +1,55 @@
+package numprint
+
+import (
+	"iter"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRows(t *testing.T) {
+	expected := []string{
+		"  0.12345 67890",
+		"10  12345 67890",
+		"20  123",
+	}
+	assert.Equal(
+		t, expected, Rows(fakeNumber{}, UpTo(23), DigitsPerRow(10)))
+	assert.Equal(
+		t,
+		[]string{"0.12345 67890 123"},
+		Rows(fakeNumber{}, UpTo(13), ShowCount(false), DigitsPerRow(0)))
+	assert.Nil(t, Rows(fakeNumber{}, Positions{}))
+	p := Positions{ranges: []PositionRange{{Start: 3, End: 1}}}
+	assert.Nil(t, Rows(fakeNumber{}, p))
+}
+
+type yieldCountingNumber struct {
+	fakeNumber
+	yields int
+}
+
+func (y *yieldCountingNumber) AllInRange(
+	start, end int) iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		for posit, digit := range y.fakeNumber.AllInRange(start, end) {
+			y.yields++
+			if !yield(posit, digit) {
+				return
+			}
+		}
+	}
+}
+
+func TestRowsSeq(t *testing.T) {
+	number := &yieldCountingNumber{}
+	var rows []string
+	for row := range RowsSeq(number, UpTo(1000), DigitsPerRow(10)) {
+		rows = append(rows, row)
+		if len(rows) == 2 {
+			break
+		}
+	}
+	assert.Equal(t, []string{"   0.12345 67890", " 10  12345 67890"}, rows)
+	assert.Equal(t, 21, number.yields)
+}
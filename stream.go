@@ -0,0 +1,72 @@
+package numprint
+
+import (
+	"fmt"
+	"io"
+)
+
+// StreamPrinter prints digits as they are pushed to it. Use StreamPrinter
+// when digits become available one at a time such as when computing them.
+// A StreamPrinter uses the same defaults as Fprint.
+type StreamPrinter struct {
+	printer *printer
+	next    int
+}
+
+// NewStreamPrinter returns a StreamPrinter that prints to w. end is one
+// past the largest position that will be printed. The StreamPrinter uses
+// end only to compute the width of the left margin. StreamPrinter ignores
+// the Reverse option.
+func NewStreamPrinter(
+	w io.Writer, end int, options ...Option) *StreamPrinter {
+	settings := printSettings(options)
+	settings.reverse = false
+	return &StreamPrinter{printer: newPrinter(w, end, settings)}
+}
+
+// Consume prints digit at the zero based position posit. Callers must
+// consume positions in increasing order. Skipped positions display as the
+// missing digit. Consuming a position that is not greater than the last
+// consumed position is an error. After an error, Consume does nothing.
+func (s *StreamPrinter) Consume(posit, digit int) {
+	if !s.printer.CanConsume() {
+		return
+	}
+	if posit < s.next {
+		s.printer.err = fmt.Errorf(
+			"numprint: position %d consumed out of order", posit)
+		return
+	}
+	s.next = posit + 1
+	s.printer.Consume(posit, digit)
+}
+
+// Finish flushes what has been printed to the underlying writer and
+// returns any error encountered including any error that Err would
+// report. Call Finish exactly once after consuming the last digit.
+func (s *StreamPrinter) Finish() error {
+	s.printer.Finish()
+	return s.printer.Err()
+}
+
+// Err returns the first error encountered so far or nil if there was
+// none. Use Err to check for errors while consuming digits. Since Finish
+// returns the error too, there is no need to call Err after Finish.
+func (s *StreamPrinter) Err() error {
+	return s.printer.Err()
+}
+
+// Checksum returns the checksum of the digits consumed so far if the
+// Checksum option was given. Otherwise, Checksum returns nil.
+func (s *StreamPrinter) Checksum() []byte {
+	if s.printer.checksum == nil {
+		return nil
+	}
+	return s.printer.checksum.Sum(nil)
+}
+
+// BytesWritten returns the number of bytes written to the underlying
+// writer so far.
+func (s *StreamPrinter) BytesWritten() int {
+	return s.printer.BytesWritten()
+}
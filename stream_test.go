@@ -0,0 +1,79 @@
+package numprint
+
+import (
+	"hash/crc32"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreamPrinter(t *testing.T) {
+	var builder strings.Builder
+	printer := NewStreamPrinter(&builder, 25, DigitsPerRow(10))
+	for i := 0; i < 25; i++ {
+		if i%7 != 3 {
+			printer.Consume(i, i%10)
+		}
+	}
+	assert.NoError(t, printer.Finish())
+	expected := `  0.012.4 56789
+10  .1234 56.89
+20  0123`
+	assert.Equal(t, expected, builder.String())
+	assert.Equal(t, builder.Len(), printer.BytesWritten())
+}
+
+func TestStreamPrinterReverse(t *testing.T) {
+	var builder strings.Builder
+	printer := NewStreamPrinter(
+		&builder, 12, DigitsPerRow(10), Reverse(true))
+	for i := 0; i < 12; i++ {
+		printer.Consume(i, i%10)
+	}
+	assert.NoError(t, printer.Finish())
+	assert.Equal(t, "  0.01234 56789\n10  01", builder.String())
+}
+
+func TestStreamPrinterOutOfOrder(t *testing.T) {
+	var builder strings.Builder
+	printer := NewStreamPrinter(&builder, 10)
+	printer.Consume(0, 1)
+	printer.Consume(1, 2)
+	assert.NoError(t, printer.Err())
+	printer.Consume(1, 3)
+	assert.ErrorContains(t, printer.Err(), "position 1")
+	printer.Consume(2, 4)
+	assert.ErrorContains(t, printer.Finish(), "position 1")
+	assert.Equal(t, "0.12", builder.String())
+}
+
+func TestStreamPrinterHighlightLast(t *testing.T) {
+	var builder strings.Builder
+	printer := NewStreamPrinter(
+		&builder, 10, ShowCount(false), HighlightLast("1"))
+	printer.Consume(0, 1)
+	printer.Consume(1, 2)
+	printer.Consume(1, 3)
+	assert.ErrorContains(t, printer.Finish(), "position 1")
+	printer = NewStreamPrinter(
+		&builder, 10, ShowCount(false), HighlightLast("1"))
+	builder.Reset()
+	printer.Consume(0, 1)
+	printer.Consume(1, 2)
+	assert.NoError(t, printer.Finish())
+	assert.Equal(t, "0.1\x1b[1m2\x1b[0m", builder.String())
+}
+
+func TestStreamPrinterChecksum(t *testing.T) {
+	var builder strings.Builder
+	printer := NewStreamPrinter(&builder, 10, Checksum(crc32.NewIEEE()))
+	printer.Consume(0, 3)
+	printer.Consume(2, 4)
+	printer.Consume(3, 1)
+	assert.NoError(t, printer.Finish())
+	expected := crc32.NewIEEE()
+	expected.Write([]byte{3, 4, 1})
+	assert.Equal(t, expected.Sum(nil), printer.Checksum())
+	assert.Nil(t, NewStreamPrinter(&builder, 10).Checksum())
+}
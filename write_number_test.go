@@ -1,6 +1,13 @@
 package numprint
 
 import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -255,6 +262,239 @@ func TestWriteWithStart(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestWriteColumnSeparator(t *testing.T) {
+	actual := Swrite(
+		newFakeNumberRange(0, 23),
+		DigitsPerRow(10),
+		DigitsPerColumn(4),
+		ColumnSeparator(" | "))
+	expected := ` 0  1234 | 5678 | 90
+10  1234 | 5678 | 90
+20  123
+`
+	assert.Equal(t, expected, actual)
+	w := &maxBytesWriter{maxBytes: 1000}
+	n, err := Fwrite(
+		w,
+		newFakeNumberRange(0, 23),
+		DigitsPerRow(10),
+		DigitsPerColumn(4),
+		ColumnSeparator(" | "))
+	assert.NoError(t, err)
+	assert.Equal(t, len(expected), n)
+}
+
+func TestWriteRowSeparator(t *testing.T) {
+	actual := Swrite(
+		newFakeNumberRange(0, 23),
+		DigitsPerRow(10),
+		RowSeparator("\n"))
+	expected := ` 0  12345 67890
+
+10  12345 67890
+
+20  123
+`
+	assert.Equal(t, expected, actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 20),
+		DigitsPerRow(10),
+		ShowCount(false),
+		TrailingLF(false),
+		RowSeparator("--\n"))
+	expected = `12345 67890
+--
+12345 67890`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteLeftAlign(t *testing.T) {
+	actual := Swrite(
+		newFakeNumberRange(0, 110),
+		DigitsPerRow(50),
+		DigitsPerColumn(10),
+		RightAlign(false))
+	expected := `0    1234567890 1234567890 1234567890 1234567890 1234567890
+50   1234567890 1234567890 1234567890 1234567890 1234567890
+100  1234567890
+`
+	assert.Equal(t, expected, actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 110),
+		DigitsPerRow(50),
+		DigitsPerColumn(10),
+		RightAlign(true))
+	expected = `  0  1234567890 1234567890 1234567890 1234567890 1234567890
+ 50  1234567890 1234567890 1234567890 1234567890 1234567890
+100  1234567890
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteCountWidth(t *testing.T) {
+	actual := Swrite(
+		newFakeNumberRange(0, 25),
+		DigitsPerRow(10),
+		DigitsPerColumn(0),
+		CountWidth(4))
+	expected := `   0  1234567890
+  10  1234567890
+  20  12345
+`
+	assert.Equal(t, expected, actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 5), DigitsPerRow(10), CountWidth(3))
+	expected = "  0  12345\n"
+	assert.Equal(t, expected, actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 25),
+		DigitsPerRow(5),
+		DigitsPerColumn(0),
+		CountWidth(1))
+	expected = `0  12345
+5  67890
+10  12345
+15  67890
+20  12345
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteCountFormat(t *testing.T) {
+	var counts []int
+	format := func(count int) string {
+		counts = append(counts, count)
+		return fmt.Sprintf("[%d]", count)
+	}
+	actual := Swrite(
+		newFakeNumberRange(0, 25),
+		DigitsPerRow(10),
+		DigitsPerColumn(0),
+		CountFormat(format))
+	expected := ` [0]  1234567890
+[10]  1234567890
+[20]  12345
+`
+	assert.Equal(t, expected, actual)
+	assert.Subset(t, counts, []int{0, 10, 20})
+	actual = Swrite(
+		newFakeNumberRange(0, 25),
+		DigitsPerRow(10),
+		DigitsPerColumn(0),
+		RightAlign(false),
+		CountFormat(func(count int) string {
+			if count == 10 {
+				return "ten"
+			}
+			return fmt.Sprintf("%d:", count)
+		}))
+	expected = `0:   1234567890
+ten  1234567890
+20:  12345
+`
+	assert.Equal(t, expected, actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 5), CountFormat(func(count int) string {
+			return fmt.Sprintf("%d:", count)
+		}))
+	assert.Equal(t, "0:  12345\n", actual)
+}
+
+func TestWriteContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var builder strings.Builder
+	n, err := FwriteContext(ctx, &builder, newFakeNumberRange(0, 10))
+	assert.Equal(t, context.Canceled, err)
+	assert.Zero(t, n)
+	assert.Empty(t, builder.String())
+	n, err = FwriteContext(
+		context.Background(), &builder, newFakeNumberRange(0, 10))
+	assert.NoError(t, err)
+	assert.Equal(t, "0  12345 67890\n", builder.String())
+	assert.Equal(t, builder.Len(), n)
+}
+
+func TestWriteReverse(t *testing.T) {
+	actual := Swrite(
+		newFakeNumberRange(0, 23), DigitsPerRow(10), Reverse(true))
+	expected := `22  32109 87654
+12  32109 87654
+ 2  321
+`
+	assert.Equal(t, expected, actual)
+	actual = Swrite(newFakeNumberRange(5, 12), Reverse(true))
+	assert.Equal(t, "11  21098 76\n", actual)
+	actual = Swrite(
+		newFakeNumberRange(5, 12),
+		Reverse(true),
+		ShowCount(false),
+		DigitsPerColumn(3))
+	assert.Equal(t, "210 987 6\n", actual)
+}
+
+func TestWriteReverseWithGaps(t *testing.T) {
+	var pb PositionsBuilder
+	actual := Swrite(
+		&sparseNumber{p: pb.AddRange(0, 3).AddRange(17, 20).Build()},
+		DigitsPerRow(5),
+		Reverse(true))
+	expected := `19  098..
+ 4  ..321`
+	assert.Equal(t, expected+"\n", actual)
+}
+
+func TestWriteHighlightReverse(t *testing.T) {
+	actual := Swrite(
+		newFakeNumberRange(0, 4),
+		Reverse(true),
+		ShowCount(false),
+		Highlight(map[int]string{0: "7"}))
+	assert.Equal(t, "432\x1b[7m1\x1b[0m\n", actual)
+}
+
+func TestWriteFitWidth(t *testing.T) {
+	actual := Swrite(newFakeNumberRange(0, 40), FitWidth(20))
+	expected := ` 0  12345 67890 1234
+14  56789 01234 5678
+28  90123 45678 90
+`
+	assert.Equal(t, expected, actual)
+	for _, line := range strings.Split(actual, "\n") {
+		assert.LessOrEqual(t, len(line), 20)
+	}
+	actual = Swrite(newFakeNumberRange(0, 12), FitWidth(17))
+	expected = "0  12345 67890 12\n"
+	assert.Equal(t, expected, actual)
+	actual = Swrite(newFakeNumberRange(0, 12), FitWidth(16))
+	expected = ` 0  12345 67890
+10  12
+`
+	assert.Equal(t, expected, actual)
+	actual = Swrite(newFakeNumberRange(0, 3), FitWidth(3))
+	expected = `0  1
+1  2
+2  3
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestTee(t *testing.T) {
+	var first, second strings.Builder
+	number := &countingNumber{fakeNumberRange: *newFakeNumberRange(0, 120)}
+	n, err := Ftee([]io.Writer{&first, &second}, number)
+	assert.NoError(t, err)
+	expected := Swrite(newFakeNumberRange(0, 120))
+	assert.Equal(t, expected, first.String())
+	assert.Equal(t, expected, second.String())
+	assert.Equal(t, len(expected), n)
+	assert.Equal(t, 1, number.allCalls)
+	_, err = Ftee(
+		[]io.Writer{&first, &maxBytesWriter{maxBytes: 10}},
+		newFakeNumberRange(0, 120))
+	assert.Error(t, err)
+}
+
 func TestWriteCountBytes(t *testing.T) {
 	w := &maxBytesWriter{maxBytes: 100000}
 
@@ -302,3 +542,453 @@ func TestWriteErrorAtAllStages2(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func TestWriteOnRow(t *testing.T) {
+	var indexes []int
+	var rows []string
+	onRow := func(rowIndex int, text string) {
+		indexes = append(indexes, rowIndex)
+		rows = append(rows, text)
+	}
+	var sb strings.Builder
+	n, err := Fwrite(
+		&sb,
+		newFakeNumberRange(0, 25),
+		DigitsPerRow(10),
+		DigitsPerColumn(5),
+		ShowCount(true),
+		OnRow(onRow))
+	assert.NoError(t, err)
+	expected := ` 0  12345 67890
+10  12345 67890
+20  12345
+`
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), n)
+	assert.Equal(t, []int{0, 1, 2}, indexes)
+	assert.Equal(
+		t,
+		[]string{" 0  12345 67890", "10  12345 67890", "20  12345"},
+		rows)
+}
+
+func TestWriteOnRowSparse(t *testing.T) {
+	var indexes []int
+	onRow := func(rowIndex int, text string) {
+		indexes = append(indexes, rowIndex)
+	}
+	var p PositionsBuilder
+	p.AddRange(25, 27)
+	number := &sparseNumber{p: p.Build()}
+	actual := Swrite(
+		number, DigitsPerRow(10), ShowCount(true), OnRow(onRow))
+	assert.Equal(t, "20  ..... 67\n", actual)
+	assert.Equal(t, []int{2}, indexes)
+}
+
+func TestWriteColumnWindow(t *testing.T) {
+	number := newFakeNumberRange(0, 35)
+	actual := Swrite(
+		number,
+		DigitsPerRow(10),
+		DigitsPerColumn(2),
+		ShowCount(true),
+		ColumnOffset(3),
+		ColumnWindow(4))
+	expected := ` 3  45 67
+13  45 67
+23  45 67
+33  45
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteColumnOffsetOnly(t *testing.T) {
+	number := newFakeNumberRange(0, 25)
+	actual := Swrite(
+		number, DigitsPerRow(10), ShowCount(true), ColumnOffset(7))
+	expected := ` 7  890
+17  890
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteColumnWindowReverse(t *testing.T) {
+	number := newFakeNumberRange(0, 20)
+	actual := Swrite(
+		number,
+		DigitsPerRow(10),
+		ShowCount(true),
+		Reverse(true),
+		ColumnWindow(3))
+	expected := `19  098
+ 9  098
+`
+	assert.Equal(t, expected, actual)
+}
+
+func TestWriteColumnWindowNoRows(t *testing.T) {
+	number := newFakeNumberRange(0, 20)
+	assert.Equal(
+		t,
+		"5  6789\n",
+		Swrite(number, DigitsPerRow(0), ColumnOffset(5), ColumnWindow(4)))
+}
+
+func TestWriteColumnOffsetHidesAll(t *testing.T) {
+	number := newFakeNumberRange(0, 20)
+	assert.Equal(
+		t, "\n", Swrite(number, DigitsPerRow(10), ColumnOffset(10)))
+}
+
+func TestLen(t *testing.T) {
+	assert.Equal(t, 0, Len(newFakeNumberRange(0, 0)))
+	assert.Equal(t, 57, Len(newFakeNumberRange(0, 57)))
+}
+
+func BenchmarkFwrite(b *testing.B) {
+	number := newFakeNumberRange(0, 100000)
+	b.ReportAllocs()
+	for b.Loop() {
+		Fwrite(io.Discard, number)
+	}
+}
+
+func BenchmarkFwriteNoColumns(b *testing.B) {
+	number := newFakeNumberRange(0, 100000)
+	b.ReportAllocs()
+	for b.Loop() {
+		Fwrite(io.Discard, number, DigitsPerRow(0), DigitsPerColumn(0))
+	}
+}
+
+func BenchmarkSwrite(b *testing.B) {
+	number := newFakeNumberRange(0, 100000)
+	b.ReportAllocs()
+	for b.Loop() {
+		Swrite(number)
+	}
+}
+
+func BenchmarkFwriteBuffered(b *testing.B) {
+	number := newFakeNumberRange(0, 100000)
+	w := bufio.NewWriter(io.Discard)
+	b.ReportAllocs()
+	for b.Loop() {
+		Fwrite(w, number)
+	}
+}
+
+func BenchmarkFwriteSmall(b *testing.B) {
+	number := newFakeNumberRange(0, 100)
+	b.ReportAllocs()
+	for b.Loop() {
+		Fwrite(io.Discard, number)
+	}
+}
+
+type flushCountingWriter struct {
+	strings.Builder
+	writes  int
+	flushes int
+}
+
+func (f *flushCountingWriter) Write(p []byte) (int, error) {
+	f.writes++
+	return f.Builder.Write(p)
+}
+
+func (f *flushCountingWriter) Flush() error {
+	f.flushes++
+	return nil
+}
+
+func TestWriteToFlusher(t *testing.T) {
+	number := newFakeNumberRange(0, 25)
+	var w flushCountingWriter
+	n, err := Fwrite(&w, number, DigitsPerRow(10))
+	assert.NoError(t, err)
+	assert.Equal(t, Swrite(number, DigitsPerRow(10)), w.String())
+	assert.Equal(t, w.Len(), n)
+	assert.Greater(t, w.writes, 1)
+	assert.Zero(t, w.flushes)
+}
+
+func TestWriteNoBuffer(t *testing.T) {
+	number := newFakeNumberRange(0, 25)
+	var w writeCountingWriter
+	n, err := Fwrite(&w, number, DigitsPerRow(10), NoBuffer(true))
+	assert.NoError(t, err)
+	assert.Equal(t, Swrite(number, DigitsPerRow(10)), w.String())
+	assert.Equal(t, w.Len(), n)
+	assert.Greater(t, w.writes, 1)
+	w = writeCountingWriter{}
+	_, err = Fwrite(&w, number, DigitsPerRow(10))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, w.writes)
+}
+
+type writeCountingWriter struct {
+	strings.Builder
+	writes int
+}
+
+func (w *writeCountingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return w.Builder.Write(p)
+}
+
+func (w *writeCountingWriter) WriteString(s string) (int, error) {
+	w.writes++
+	return w.Builder.WriteString(s)
+}
+
+func TestWriteShortWrites(t *testing.T) {
+	number := newFakeNumberRange(0, 100)
+	w := &shortWriter{}
+	n, err := Fwrite(w, number)
+	assert.ErrorIs(t, err, io.ErrShortWrite)
+	assert.Equal(t, w.Len(), n)
+	w = &shortWriter{}
+	n, err = Fwrite(w, number, NoBuffer(true))
+	assert.ErrorIs(t, err, io.ErrShortWrite)
+	assert.Equal(t, w.Len(), n)
+	assert.Equal(t, Swrite(number)[:n], w.String())
+}
+
+// shortWriter writes only the first half of what it is asked to write
+// without reporting an error.
+type shortWriter struct {
+	strings.Builder
+}
+
+func (s *shortWriter) Write(p []byte) (int, error) {
+	return s.Builder.Write(p[:len(p)/2])
+}
+
+type maxBytesFlusher struct {
+	maxBytesWriter
+}
+
+func (m *maxBytesFlusher) Flush() error {
+	return nil
+}
+
+func TestWriteToFlusherError(t *testing.T) {
+	number := newFakeNumberRange(0, 1000)
+	for i := 0; i < 1300; i += 7 {
+		w := &maxBytesFlusher{maxBytesWriter{maxBytes: i}}
+		n, err := Fwrite(w, number)
+		assert.Equal(t, i, n)
+		assert.Error(t, err)
+	}
+}
+
+func TestWriteGapSkipReverse(t *testing.T) {
+	var pb PositionsBuilder
+	pb.AddRange(0, 3).AddRange(20, 23)
+	number := &sparseNumber{p: pb.Build()}
+	assert.Equal(
+		t,
+		" 0  321\n 3  321\n",
+		Swrite(
+			number,
+			DigitsPerRow(3),
+			Reverse(true),
+			GapFill(GapSkip)))
+}
+
+func TestWriteRuler(t *testing.T) {
+	number := newFakeNumberRange(0, 25)
+	expected := `    0     5     10
+ 0  12345 67890 12
+12  34567 89012 34
+24  5
+`
+	assert.Equal(
+		t,
+		expected,
+		Swrite(number, DigitsPerRow(12), Ruler(true)))
+	expected = `    3  5  7
+ 3  45 67 8
+13  45 67 8
+23  45
+`
+	assert.Equal(
+		t,
+		expected,
+		Swrite(
+			number,
+			DigitsPerRow(10),
+			DigitsPerColumn(2),
+			ColumnOffset(3),
+			ColumnWindow(5),
+			Ruler(true)))
+	assert.Equal(
+		t,
+		"0  2  4  6  8  10 12 14\n12 34 56 78 90 12 34 5\n",
+		Swrite(
+			number,
+			DigitsPerRow(0),
+			DigitsPerColumn(2),
+			ShowCount(false),
+			Ruler(true),
+			ColumnWindow(15)))
+	assert.Equal(t, "\n", Swrite(newFakeNumberRange(0, 0), Ruler(true)))
+}
+
+func TestWriteMarkdownFence(t *testing.T) {
+	number := newFakeNumberRange(0, 12)
+	assert.Equal(
+		t,
+		"```text\n 0  12345\n 5  67890\n10  12\n```\n",
+		Swrite(
+			number,
+			DigitsPerRow(5),
+			MarkdownFence("text")))
+	assert.Equal(
+		t,
+		"```\n12345 67890 12\n```",
+		Swrite(
+			number,
+			ShowCount(false),
+			TrailingLF(false),
+			MarkdownFence("")))
+	assert.Equal(
+		t,
+		"```\n```\n",
+		Swrite(newFakeNumberRange(0, 0), MarkdownFence("")))
+}
+
+func TestWriteMarkdownFenceBackticks(t *testing.T) {
+	number := newFakeNumberRange(0, 12)
+	var sb strings.Builder
+	n, err := Fwrite(
+		&sb,
+		number,
+		ShowCount(false),
+		ColumnSeparator("```"),
+		MarkdownFence("text"))
+	assert.NoError(t, err)
+	expected := "````text\n12345```67890```12\n````\n"
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), n)
+}
+
+func TestWriteHighlightLast(t *testing.T) {
+	actual := Swrite(
+		newFakeNumberRange(0, 4), ShowCount(false), HighlightLast("1"))
+	assert.Equal(t, "123\x1b[1m4\x1b[0m\n", actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 4),
+		ShowCount(false),
+		HighlightLast("1"),
+		NoColor(true))
+	assert.Equal(t, "1234\n", actual)
+	actual = Swrite(
+		newFakeNumberRange(0, 4),
+		ShowCount(false),
+		Reverse(true),
+		Highlight(map[int]string{0: "7", 3: "7"}),
+		HighlightLast("1"))
+	assert.Equal(t, "\x1b[7m4\x1b[0m32\x1b[1m1\x1b[0m\n", actual)
+	assert.Equal(
+		t, "\n", Swrite(newFakeNumberRange(0, 0), HighlightLast("1")))
+}
+
+func TestWriteCountThreshold(t *testing.T) {
+	assert.Equal(
+		t,
+		"12345 67890\n12345\n",
+		Swrite(
+			newFakeNumberRange(0, 15), DigitsPerRow(10), CountThreshold(16)))
+	assert.Equal(
+		t,
+		" 0  12345 67890\n10  12345 6\n",
+		Swrite(
+			newFakeNumberRange(0, 16), DigitsPerRow(10), CountThreshold(16)))
+}
+
+func TestWriteTrailingLFLastRow(t *testing.T) {
+	number := newFakeNumberRange(0, 25)
+	var sb strings.Builder
+	n, err := Fwrite(
+		&sb,
+		number,
+		DigitsPerRow(10),
+		RowSeparator("\n"),
+		TrailingLF(false))
+	assert.NoError(t, err)
+	expected := " 0  12345 67890\n\n10  12345 67890\n\n20  12345"
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), n)
+	sb.Reset()
+	n, err = Fprint(
+		&sb, number, UpTo(25), DigitsPerRow(10), TrailingLF(true))
+	assert.NoError(t, err)
+	expected = "  0.12345 67890\n10  12345 67890\n20  12345\n"
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), n)
+}
+
+func TestWriteLineEnding(t *testing.T) {
+	number := newFakeNumberRange(0, 25)
+	var sb strings.Builder
+	n, err := Fwrite(
+		&sb,
+		number,
+		DigitsPerRow(10),
+		RowSeparator("-"),
+		Ruler(true),
+		LineEnding("\r\n"))
+	assert.NoError(t, err)
+	expected := "    0     5\r\n 0  12345 67890\r\n-10  12345 67890\r\n" +
+		"-20  12345\r\n"
+	assert.Equal(t, expected, sb.String())
+	assert.Equal(t, len(expected), n)
+	assert.Equal(
+		t,
+		"```\r\n12345\r\n```\r\n",
+		Swrite(
+			newFakeNumberRange(0, 5),
+			ShowCount(false),
+			MarkdownFence(""),
+			LineEnding("\r\n")))
+}
+
+func TestWriteChecksum(t *testing.T) {
+	h := sha256.New()
+	Swrite(newFakeNumberRange(0, 20), Checksum(h))
+	expected := sha256.Sum256(
+		[]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 0})
+	assert.Equal(t, expected[:], h.Sum(nil))
+}
+
+func TestWriteAppendChecksumLine(t *testing.T) {
+	digest := sha256.Sum256([]byte{1, 2, 3, 4, 5})
+	line := "# checksum: " + hex.EncodeToString(digest[:])
+	var sb strings.Builder
+	written, err := Fwrite(
+		&sb, newFakeNumberRange(0, 5), Checksum(sha256.New()),
+		AppendChecksumLine(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "0  12345\n"+line+"\n", sb.String())
+	assert.Equal(t, sb.Len(), written)
+	assert.Equal(
+		t,
+		"0.12345\n"+line,
+		Sprint(
+			fakeNumber{}, UpTo(5), Checksum(sha256.New()),
+			AppendChecksumLine(true)))
+	assert.Equal(
+		t,
+		"```\r\n0  12345\r\n```\r\n"+line+"\r\n",
+		Swrite(
+			newFakeNumberRange(0, 5), Checksum(sha256.New()),
+			AppendChecksumLine(true), MarkdownFence(""),
+			LineEnding("\r\n")))
+	assert.Equal(
+		t,
+		"0  12345\n",
+		Swrite(newFakeNumberRange(0, 5), AppendChecksumLine(true)))
+}